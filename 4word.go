@@ -27,3 +27,17 @@ func (m CharacterMeta9700) Meta() CharacterMeta {
 		Spacing: m.BlanksLeft & 0x8000 == 0x8000,
 	}
 }
+
+func (m CharacterMeta) meta9700() CharacterMeta9700 {
+	raw := CharacterMeta9700{
+		BlanksLeft: uint16(m.BlanksLeft & 0x7FFF),
+		GlyphOffset: uint16(m.GlyphOffset),
+		BitmapSize: m.BitmapSize,
+		CellWidth: uint16(m.CellWidth),
+	}
+
+	if m.Spacing {
+		raw.BlanksLeft |= 0x8000
+	}
+	return raw
+}
@@ -22,3 +22,18 @@ func (m CharacterMeta5Word) Meta() CharacterMeta {
 		Spacing: m.BlanksLeft & 0x8000 == 0x8000,
 	}
 }
+
+func (m CharacterMeta) meta5Word() CharacterMeta5Word {
+	raw := CharacterMeta5Word{
+		BlanksLeft: uint16(m.BlanksLeft & 0x7FFF),
+		GlyphOffset: uint16(m.GlyphOffset),
+		Unknown: m.Unknown,
+		BitmapSize: m.BitmapSize,
+		CellWidth: uint16(m.CellWidth),
+	}
+
+	if m.Spacing {
+		raw.BlanksLeft |= 0x8000
+	}
+	return raw
+}
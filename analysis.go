@@ -0,0 +1,374 @@
+package xeroxfont
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CorpusSample is one font's known properties and the values of the fields
+// that aren't understood yet.
+type CorpusSample struct {
+	File string
+
+	Format      Format
+	Orientation Orientation
+	FontType    byte
+	PixelHeight int
+
+	// Decoded from the font's name.  Zero if the name doesn't follow the
+	// naming convention.
+	PointSize int
+	Weight    Weight
+
+	// Zero if there is no extra header.
+	FontFormat FontFormat
+
+	// Number of times each value was seen for each field, keyed by field
+	// name.  Header fields have one value.  Metadata fields count every
+	// character that has a glyph.
+	Fields map[string]map[string]int
+}
+
+// corpusProperties are the known properties that fields are compared with.
+var corpusProperties = []string{"Format", "Orientation", "FontType", "PixelHeight", "PointSize", "Weight", "FontFormat"}
+
+// Property returns one of the font's known properties by name: Format,
+// Orientation, FontType, PixelHeight, PointSize, Weight, or FontFormat.
+func (s *CorpusSample) Property(name string) string {
+	switch name {
+	case "Format":
+		return s.Format.String()
+	case "Orientation":
+		return s.Orientation.String()
+	case "FontType":
+		return fontTypeName(s.FontType)
+	case "PixelHeight":
+		return strconv.Itoa(s.PixelHeight)
+	case "PointSize":
+		if s.PointSize == 0 {
+			return ""
+		}
+		return strconv.Itoa(s.PointSize)
+	case "Weight":
+		if s.Weight == 0 {
+			return ""
+		}
+		return s.Weight.String()
+	case "FontFormat":
+		if s.FontFormat == 0 {
+			return "none"
+		}
+		return fmt.Sprintf("$%02X", byte(s.FontFormat))
+	}
+	return ""
+}
+
+// Value returns all the values of a field in one string, most common first,
+// with counts if there is more than one.
+func (s *CorpusSample) Value(field string) string {
+	values := s.Fields[field]
+	keys := []string{}
+	for v := range values {
+		keys = append(keys, v)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if values[keys[i]] != values[keys[j]] {
+			return values[keys[i]] > values[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	if len(keys) == 1 {
+		return keys[0]
+	}
+
+	parts := []string{}
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s (%d)", k, values[k]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (s *CorpusSample) add(field, value string) {
+	if s.Fields[field] == nil {
+		s.Fields[field] = map[string]int{}
+	}
+	s.Fields[field][value]++
+}
+
+// SampleLayout collects the unknown and unused header fields of a font, the
+// unknown word of 5Word metadata entries, and the sign of each glyph's
+// BitmapSize.
+func SampleLayout(file string, l *Layout) *CorpusSample {
+	s := &CorpusSample{
+		File:        file,
+		Format:      l.Format,
+		Orientation: l.Header.Orientation,
+		FontType:    l.Header.FontType,
+		PixelHeight: int(l.Header.PixelHeight),
+		Fields:      map[string]map[string]int{},
+	}
+
+	names := []string{string(l.Header.FontName[:])}
+	if l.Extra != nil {
+		s.FontFormat = l.Extra.FontFormat
+		names = append(names, string(l.Extra.FontNameA[:]), string(l.Extra.FontNameB[:]))
+	}
+	for _, name := range names {
+		if id, err := ParseFontID(name); err == nil {
+			s.PointSize = id.PointSize
+			s.Weight = id.Weight
+			break
+		}
+	}
+
+	for _, r := range l.Regions {
+		if r.Kind != RegionUnknown && r.Kind != RegionUnused {
+			continue
+		}
+		s.add(r.Name, fieldValue(l.Data[r.Offset:r.Offset+r.Size]))
+	}
+
+	for _, m := range l.Meta {
+		if m.IsSpace() {
+			continue
+		}
+
+		if l.Format == Format5Word {
+			s.add("Meta.Unknown", fmt.Sprintf("%04X", m.Unknown))
+		}
+
+		switch {
+		case m.BitmapSize < 0:
+			s.add("Meta.BitmapSize sign", "negative")
+		case m.BitmapSize > 0:
+			s.add("Meta.BitmapSize sign", "positive")
+		default:
+			s.add("Meta.BitmapSize sign", "zero")
+		}
+	}
+
+	return s
+}
+
+// fieldValue is the hex of a field's bytes.  Fields longer than 16 bytes
+// only list their non-zero bytes, as offset=value.
+func fieldValue(raw []byte) string {
+	if len(raw) <= 16 {
+		return fmt.Sprintf("%X", raw)
+	}
+
+	set := []string{}
+	for i, b := range raw {
+		if b != 0 {
+			set = append(set, fmt.Sprintf("+%02X=%02X", i, b))
+		}
+	}
+
+	if len(set) == 0 {
+		return fmt.Sprintf("00 x%d", len(raw))
+	}
+	return strings.Join(set, " ")
+}
+
+// Corpus is a set of fonts being compared.
+type Corpus struct {
+	Samples []*CorpusSample
+}
+
+// AddFile reads a font file's layout and adds it to the corpus.
+func (c *Corpus) AddFile(filename string, format Format) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	l, err := ReadLayout(data, format)
+	if err != nil {
+		return err
+	}
+
+	c.Samples = append(c.Samples, SampleLayout(filename, l))
+	return nil
+}
+
+// Fields returns the name of every field seen in the corpus, sorted.
+func (c *Corpus) Fields() []string {
+	seen := map[string]bool{}
+	fields := []string{}
+	for _, s := range c.Samples {
+		for f := range s.Fields {
+			if !seen[f] {
+				seen[f] = true
+				fields = append(fields, f)
+			}
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// FontTable has a row for each font with its properties and field values.
+func (c *Corpus) FontTable() [][]string {
+	fields := c.Fields()
+	rows := [][]string{append(append([]string{"File"}, corpusProperties...), fields...)}
+	for _, s := range c.Samples {
+		row := []string{s.File}
+		for _, p := range corpusProperties {
+			row = append(row, s.Property(p))
+		}
+		for _, f := range fields {
+			row = append(row, s.Value(f))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// ValueTable has a row for each value of each field with the number of fonts
+// and characters it was seen in, and the properties of those fonts.
+func (c *Corpus) ValueTable() [][]string {
+	rows := [][]string{append([]string{"Field", "Value", "Fonts", "Count"}, corpusProperties...)}
+	for _, f := range c.Fields() {
+		type stats struct {
+			fonts, count int
+			props        []map[string]bool
+		}
+
+		byValue := map[string]*stats{}
+		for _, s := range c.Samples {
+			for v, n := range s.Fields[f] {
+				st := byValue[v]
+				if st == nil {
+					st = &stats{props: make([]map[string]bool, len(corpusProperties))}
+					for i := range st.props {
+						st.props[i] = map[string]bool{}
+					}
+					byValue[v] = st
+				}
+
+				st.fonts++
+				st.count += n
+				for i, p := range corpusProperties {
+					st.props[i][s.Property(p)] = true
+				}
+			}
+		}
+
+		values := []string{}
+		for v := range byValue {
+			values = append(values, v)
+		}
+		sort.Slice(values, func(i, j int) bool {
+			a, b := byValue[values[i]], byValue[values[j]]
+			if a.count != b.count {
+				return a.count > b.count
+			}
+			return values[i] < values[j]
+		})
+
+		for _, v := range values {
+			st := byValue[v]
+			row := []string{f, v, strconv.Itoa(st.fonts), strconv.Itoa(st.count)}
+			for _, seen := range st.props {
+				row = append(row, joinKeys(seen))
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+func joinKeys(set map[string]bool) string {
+	keys := []string{}
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ", ")
+}
+
+// CorrelationTable has a row for each field with how well each property
+// predicts its value.  A score is the fraction of fonts whose value is the
+// most common one among fonts with the same property value, so 1.00 means
+// the field is determined by the property.  Fields with only one value
+// always score 1.00.
+func (c *Corpus) CorrelationTable() [][]string {
+	rows := [][]string{append([]string{"Field", "Values"}, corpusProperties...)}
+	for _, f := range c.Fields() {
+		distinct := map[string]bool{}
+		for _, s := range c.Samples {
+			distinct[s.Value(f)] = true
+		}
+
+		row := []string{f, strconv.Itoa(len(distinct))}
+		for _, p := range corpusProperties {
+			groups := map[string]map[string]int{}
+			for _, s := range c.Samples {
+				prop := s.Property(p)
+				if groups[prop] == nil {
+					groups[prop] = map[string]int{}
+				}
+				groups[prop][s.Value(f)]++
+			}
+
+			agree := 0
+			for _, values := range groups {
+				best := 0
+				for _, n := range values {
+					best = max(best, n)
+				}
+				agree += best
+			}
+			row = append(row, fmt.Sprintf("%.2f", float64(agree)/float64(max(1, len(c.Samples)))))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// WriteCSV writes a table as CSV.
+func WriteCSV(w io.Writer, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	err := cw.WriteAll(rows)
+	if err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// WriteMarkdownTable writes a table as a Markdown table.  The first row is
+// the header.
+func WriteMarkdownTable(w io.Writer, rows [][]string) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	sb := &strings.Builder{}
+	line := func(cells []string) {
+		sb.WriteString("|")
+		for _, cell := range cells {
+			cell = strings.ReplaceAll(cell, "|", "\\|")
+			fmt.Fprintf(sb, " %s |", cell)
+		}
+		sb.WriteString("\n")
+	}
+
+	line(rows[0])
+	rule := []string{}
+	for range rows[0] {
+		rule = append(rule, "---")
+	}
+	line(rule)
+	for _, row := range rows[1:] {
+		line(row)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
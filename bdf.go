@@ -2,71 +2,292 @@ package xeroxfont
 
 import (
 	"fmt"
+	"image"
+	"math"
+	"strconv"
 	"strings"
-	"slices"
 	//"image/color"
 	//"os"
 )
 
+// BDFOptions controls the names and metrics written by BDFWithOptions().
+// Zero values are replaced with defaults.
+type BDFOptions struct {
+	// Resolution used to convert between pixels and points.  Defaults to
+	// DeviceDPI.
+	DPI int
+
+	// Point size of the font.  Defaults to the size in the font's name, or
+	// PixelHeight at DPI.
+	PointSize float64
+
+	// Defaults for these come from the font's FontID if it has one.
+	Foundry string // Defaults to "Xerox"
+	Family  string // Defaults to the font name
+	Weight  string // Defaults to "Medium"
+	Slant   string // Defaults to "R"
+
+	// Character set of the font.  Defaults to MICROSOFT-CP1252, which
+	// matches the glyph names in PostscriptNames, or ISO10646-1 if the font
+	// has an Encoding.  With ISO10646 characters are written with their
+	// Unicode values, otherwise with the font's own codes.
+	Registry string
+	Encoding string
+}
+
+func (o *BDFOptions) setDefaults(f *Font) {
+	if o.DPI <= 0 {
+		o.DPI = DeviceDPI
+	}
+
+	if id, err := f.FontID(); err == nil {
+		if o.Family == "" {
+			o.Family = id.FamilyName()
+		}
+
+		if o.Weight == "" && id.Weight == WeightBold {
+			o.Weight = "Bold"
+		}
+
+		if o.PointSize <= 0 {
+			o.PointSize = float64(id.PointSize)
+		}
+	}
+
+	if o.PointSize <= 0 {
+		o.PointSize = float64(f.Header.PixelHeight) * 72 / float64(o.DPI)
+	}
+
+	if o.Foundry == "" {
+		o.Foundry = "Xerox"
+	}
+
+	if o.Family == "" {
+		o.Family = fieldString(f.Header.FontName[:])
+	}
+
+	if o.Weight == "" {
+		o.Weight = "Medium"
+	}
+
+	if o.Slant == "" {
+		o.Slant = "R"
+	}
+
+	if o.Registry == "" && o.Encoding == "" && f.Encoding != nil {
+		o.Registry = "ISO10646"
+		o.Encoding = "1"
+	}
+
+	if o.Registry == "" {
+		o.Registry = "MICROSOFT"
+	}
+
+	if o.Encoding == "" {
+		o.Encoding = "CP1252"
+	}
+}
+
+// BDF returns the font in the Glyph Bitmap Distribution Format.  If ptSize is
+// zero, the point size is the size in the font's name, or PixelHeight at
+// DeviceDPI.
 func (f *Font) BDF(ptSize int) string {
+	return f.BDFWithOptions(BDFOptions{PointSize: float64(ptSize)})
+}
+
+func (f *Font) BDFWithOptions(opts BDFOptions) string {
+	opts.setDefaults(f)
 	sb := &strings.Builder{}
 
-	name := []string{
-		strings.TrimSpace(string(f.Header.FontName[:])),
-		strings.TrimSpace(string(f.Header.Revision[:])),
-		strings.TrimSpace(string(f.Header.Version[:])),
-		strings.TrimSpace(string(f.Header.Library[:])),
+	chars := []*Character{}
+	for _, r := range f.sortedRunes() {
+		c := f.Characters[r]
+		if !c.IsSpace || c.Value == ' ' {
+			chars = append(chars, c)
+		}
+	}
+
+	spacing := "P"
+	if f.Header.FontType == 'F' {
+		spacing = "M"
+	}
+
+	avgWidth := 0
+	if len(chars) > 0 {
+		for _, c := range chars {
+			avgWidth += c.CellWidth
+		}
+		avgWidth = int(math.Round(float64(avgWidth) * 10 / float64(len(chars))))
 	}
 
-	name = slices.DeleteFunc(name, func(s string) bool { return s == "" })
+	pointSize := int(math.Round(opts.PointSize * 10))
+	pixelSize := int(f.Header.PixelHeight)
+
+	name := fmt.Sprintf("-%s-%s-%s-%s-Normal--%d-%d-%d-%d-%s-%d-%s-%s",
+		xlfdField(opts.Foundry),
+		xlfdField(opts.Family),
+		xlfdField(opts.Weight),
+		xlfdField(opts.Slant),
+		pixelSize,
+		pointSize,
+		opts.DPI,
+		opts.DPI,
+		spacing,
+		avgWidth,
+		xlfdField(opts.Registry),
+		xlfdField(opts.Encoding),
+	)
 
 	fmt.Fprintln(sb, "STARTFONT 2.2")
-	fmt.Fprintf(sb, "FONT %s\n", strings.Join(name, " "))
-	fmt.Fprintf(sb, "SIZE %d 300 300\n", ptSize)
-	// TODO: width, height, offset X, offset Y
-	//fmt.Fprintf(sb, "FONTBOUNDINGBOX %d %d %d %d\n", f.Header.FixedWidth, int(f.Header.DistanceAbove)+int(f.Header.DistanceBelow), 0, int(f.Header.DistanceBelow)*-1)
-	fmt.Fprintf(sb, "FONTBOUNDINGBOX %d %d %d %d\n", f.Header.FixedWidth, f.Header.PixelHeight, 0, int(f.Header.DistanceBelow)*-1)
+	fmt.Fprintf(sb, "FONT %s\n", name)
+	fmt.Fprintf(sb, "SIZE %d %d %d\n", int(math.Round(opts.PointSize)), opts.DPI, opts.DPI)
 
-	props := make(map[string]any)
-	props["FONT_ASCENT"] = int(f.Header.DistanceAbove)
-	props["FONT_DESCENT"] = int(f.Header.DistanceBelow)
+	bbox := image.Rectangle{}
+	for _, c := range chars {
+		bbox = bbox.Union(c.bdfBounds(f.Header))
+	}
+	fmt.Fprintf(sb, "FONTBOUNDINGBOX %d %d %d %d\n", bbox.Dx(), bbox.Dy(), bbox.Min.X, bbox.Min.Y)
 
-	fmt.Fprintf(sb, "STARTPROPERTIES %d\n", len(props))
-	for k, v := range props {
-		fmt.Fprintln(sb, k, v)
+	defaultChar := ' '
+	if _, ok := f.Characters[defaultChar]; !ok && len(chars) > 0 {
+		defaultChar = chars[0].Value
+	}
+	defaultEnc, defaultOK := f.bdfEncoding(defaultChar, opts)
+
+	props := []struct{
+		name string
+		value any
+	}{
+		{"FOUNDRY", opts.Foundry},
+		{"FAMILY_NAME", opts.Family},
+		{"WEIGHT_NAME", opts.Weight},
+		{"SLANT", opts.Slant},
+		{"SETWIDTH_NAME", "Normal"},
+		{"ADD_STYLE_NAME", ""},
+		{"PIXEL_SIZE", pixelSize},
+		{"POINT_SIZE", pointSize},
+		{"RESOLUTION_X", opts.DPI},
+		{"RESOLUTION_Y", opts.DPI},
+		{"SPACING", spacing},
+		{"AVERAGE_WIDTH", avgWidth},
+		{"CHARSET_REGISTRY", opts.Registry},
+		{"CHARSET_ENCODING", opts.Encoding},
+		{"FONT_ASCENT", int(f.Header.DistanceAbove)},
+		{"FONT_DESCENT", int(f.Header.DistanceBelow)},
+		{"DEFAULT_CHAR", defaultEnc},
+	}
+
+	// The default character has to be in the character set.
+	if !defaultOK {
+		props = props[:len(props)-1]
 	}
-	fmt.Fprintln(sb, "ENDPROPERTIES")
 
-	l := 0
-	for _, c := range f.Characters {
-		if !c.IsSpace && c.Value != ' ' {
-			l++
+	fmt.Fprintf(sb, "STARTPROPERTIES %d\n", len(props))
+	for _, p := range props {
+		switch v := p.value.(type) {
+		case string:
+			fmt.Fprintf(sb, "%s \"%s\"\n", p.name, strings.ReplaceAll(v, `"`, `""`))
+		default:
+			fmt.Fprintln(sb, p.name, v)
 		}
 	}
-	fmt.Fprintf(sb, "CHARS %d\n", l)
+	fmt.Fprintln(sb, "ENDPROPERTIES")
+
+	fmt.Fprintf(sb, "CHARS %d\n", len(chars))
 
-	for _, c := range f.Characters {
-		c.bdf(sb, f.Header)
+	// Scalable widths are in 1/1000ths of the point size.
+	swidthScale := 1000 * 72 / (opts.PointSize * float64(opts.DPI))
+	for _, c := range chars {
+		encoding := fmt.Sprintf("-1 %d", c.Value)
+		if enc, ok := f.bdfEncoding(c.Value, opts); ok {
+			encoding = strconv.Itoa(enc)
+		}
+		c.bdf(sb, f.Header, f.glyphName(c.Value), encoding, int(math.Round(float64(c.CellWidth) * swidthScale)))
 	}
 
 	fmt.Fprintln(sb, "ENDFONT")
 	return sb.String()
 }
 
-func (c *Character) bdf(sb *strings.Builder, h *FontHeader) {
+// bdfEncoding returns the value a character is written with in the BDF
+// ENCODING line.  Characters that have no value in the font's character set
+// return false, and are written as -1 followed by their code.
+func (f *Font) bdfEncoding(code rune, opts BDFOptions) (int, bool) {
+	if strings.EqualFold(opts.Registry, "ISO10646") {
+		u, ok := f.UnicodeFor(code)
+		return int(u), ok
+	}
+
+	if f.Encoding == nil && code > 0xFF {
+		return 0, false
+	}
+	return int(code), true
+}
+
+// xlfdField removes characters that aren't allowed in an XLFD field.
+func xlfdField(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '-', '?', '*', ',', '"':
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// bdfBounds returns the BBX of the character as a rectangle with Y going up
+// from the baseline.
+func (c *Character) bdfBounds(h *FontHeader) image.Rectangle {
+	yoff := c.BlanksLeft-int(h.DistanceBelow)
+	return image.Rect(0, yoff, c.Width(), yoff+c.Height())
+}
+
+func glyphName(r rune) string {
+	if name, ok := PostscriptNames[r]; ok {
+		return name
+	}
+	return fmt.Sprintf("uni%04X", r)
+}
+
+// glyphName returns the PostScript name of one of the font's characters,
+// going through the font's Encoding if it has one.  Characters without a
+// Unicode value are named after their code.
+func (f *Font) glyphName(code rune) string {
+	if f.Encoding == nil && code <= 0xFF {
+		return glyphName(code)
+	}
+
+	u, ok := f.UnicodeFor(code)
+	if !ok {
+		return fmt.Sprintf("code%04X", code)
+	}
+
+	if cp, ok := UnicodeToCode(u); ok {
+		if name, ok := PostscriptNames[cp]; ok {
+			return name
+		}
+	}
+
+	if u > 0xFFFF {
+		return fmt.Sprintf("u%05X", u)
+	}
+	return fmt.Sprintf("uni%04X", u)
+}
+
+func (c *Character) bdf(sb *strings.Builder, h *FontHeader, name, encoding string, swidth int) {
 	if c.IsSpace && c.Value != ' ' {
 		return
 	}
 
-	fmt.Fprintf(sb, "STARTCHAR %s\n", PostscriptNames[c.Value])
-	fmt.Fprintf(sb, "ENCODING %d\n", int(c.Value))
+	fmt.Fprintf(sb, "STARTCHAR %s\n", name)
+	fmt.Fprintf(sb, "ENCODING %s\n", encoding)
 	//fmt.Printf("\nCharacter %s (0x%02X)\n", PostscriptNames[c.Value], c.Value)
 	// BBX BBw BBh BBxoff0x BByoff0y
 	//yoff := c.Height() - ((int(h.DistanceAbove) + int(h.DistanceBelow)) - c.BlanksLeft)
 	fmt.Fprintf(sb, "BBX %d %d %d %d\n", c.Width(), c.Height(), 0, c.BlanksLeft-int(h.DistanceBelow))
 	//fmt.Fprintf(sb, "BBX %d %d %d %d\n", c.CellWidth, c.Height(), 0, yoff)
 
-	fmt.Fprintln(sb, "SWIDTH 300 0")
+	fmt.Fprintf(sb, "SWIDTH %d 0\n", swidth)
 	fmt.Fprintf(sb, "DWIDTH %d 0\n", c.CellWidth)
 
 	fmt.Fprintln(sb, "BITMAP")
@@ -19,6 +19,11 @@ type Character struct {
 
 	BitmapSize int16
 	GlyphCount int
+
+	// CharacterMeta5Word.Unknown from 5Word fonts.  Zero writes
+	// Default5WordUnknown.
+	Unknown uint16
+
 	glyph []byte
 	img image.Image
 	mask image.Image
@@ -28,6 +33,35 @@ func From5Word(raw *CharacterMeta5Word) (*Character, error) {
 	return nil, fmt.Errorf("From5Word() not implemented")
 }
 
+const (
+	MaxGlyphWidth  int = 0x1FF
+	MaxGlyphHeight int = 512
+)
+
+// packBitmapSize packs glyph dimensions into the BitmapSize field.  Fonts
+// store this value negated; see Width() and Height() for unpacking.
+func packBitmapSize(width, height int) int16 {
+	return -int16(((height/8)-1)*512 + width)
+}
+
+// glyphSize returns the number of bytes a glyph uses, including the padding
+// that rounds it up to a full 16-bit word.
+func glyphSize(width, height int) int {
+	size := width*(height/8)
+	if size % 2 != 0 {
+		size++
+	}
+	return size
+}
+
+// swapWords swaps the byte order of each 16-bit word in data.  A trailing odd
+// byte is left as-is.
+func swapWords(data []byte) {
+	for i := 0; i < len(data)-1; i += 2 {
+		data[i], data[i+1] = data[i+1], data[i]
+	}
+}
+
 func abs(x int16) int16 {
 	if x < 0 {
 		return -x
@@ -55,6 +89,45 @@ func (c *Character) MarshalJSON() ([]byte, error) {
 	return json.MarshalIndent(data, "", "    ")
 }
 
+// UnmarshalJSON reads the metrics written by MarshalJSON.  The glyph is blank
+// and sized to the Width and Height values.
+func (c *Character) UnmarshalJSON(raw []byte) error {
+	data := struct {
+		Value rune
+		IsSpace bool
+		BlanksLeft int
+		CellWidth int
+		Width int
+		Height int
+	}{}
+
+	err := json.Unmarshal(raw, &data)
+	if err != nil {
+		return err
+	}
+
+	*c = Character{Value: data.Value}
+	c.SetIsSpace(true)
+	if !data.IsSpace {
+		err = c.Resize(data.Width, data.Height)
+		if err != nil {
+			return fmt.Errorf("character 0x%02X: %w", data.Value, err)
+		}
+	}
+
+	err = c.SetBlanksLeft(data.BlanksLeft)
+	if err != nil {
+		return fmt.Errorf("character 0x%02X: %w", data.Value, err)
+	}
+
+	err = c.SetCellWidth(data.CellWidth)
+	if err != nil {
+		return fmt.Errorf("character 0x%02X: %w", data.Value, err)
+	}
+
+	return nil
+}
+
 func (c *Character) Height() int {
 	return int(abs(c.BitmapSize >> 9))*8
 }
@@ -83,88 +156,242 @@ func (c *Character) Mask() image.Image {
 		return c.mask
 	}
 
-	height := c.Height()
-	width  := c.Width()
+	c.mask = c.render(color.White, color.Transparent)
+	return c.mask
+}
 
-	var img *image.RGBA
-	if height % 8 != 0 {
-		img = image.NewRGBA(image.Rect(0, 0, width, height+4))
-	} else {
-		img = image.NewRGBA(image.Rect(0, 0, width, height))
+func (c *Character) Image() image.Image {
+	if c.img != nil {
+		return c.img
 	}
 
+	c.img = c.render(color.White, color.Black)
+	return c.img
+}
+
+func (c *Character) render(on, off color.Color) image.Image {
+	height := c.Height()
+	width  := c.Width()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
 	if c.IsSpace {
 		return img
 	}
 
-	x, y := 0, height
-	on := color.White
-
-	c.GlyphCount = 0
-	for _, b := range c.glyph {
-		for i := 7; i > -1; i-- {
-			v := (b >> i) & 0x01
-			if v == 1 {
-				img.Set(height-y, height-x-1, on)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if c.Pixel(x, y) {
+				img.Set(x, y, on)
+			} else {
+				img.Set(x, y, off)
 			}
-			x++
-		}
-		if x >= height {
-			y--
-			x = 0
 		}
-		c.GlyphCount++
 	}
 
-	c.img = img
+	c.GlyphCount = len(c.glyph)
 	return img
 }
 
-func (c *Character) Image() image.Image {
-	if c.img != nil {
-		return c.img
+// glyphIndex returns the byte index and bit mask in the raw glyph data for
+// the pixel at (x, y).  Glyphs are stored rotated 90 degrees clockwise, so
+// each column of the upright glyph is stored bottom to top.
+func (c *Character) glyphIndex(x, y int) (int, byte) {
+	bit := c.Height() - y - 1
+	idx := x*(c.Height()/8) + bit/8
+	return idx, 0x80 >> (bit % 8)
+}
+
+// Pixel returns true if the pixel at (x, y) is set.  (0, 0) is the top left
+// of the upright glyph.  Pixels outside of the glyph are never set.
+func (c *Character) Pixel(x, y int) bool {
+	if c.IsSpace || x < 0 || y < 0 || x >= c.Width() || y >= c.Height() {
+		return false
 	}
 
-	height := c.Height()
-	width  := c.Width()
+	idx, mask := c.glyphIndex(x, y)
+	if idx >= len(c.glyph) {
+		return false
+	}
+	return c.glyph[idx] & mask != 0
+}
 
-	var img *image.RGBA
-	if height % 8 != 0 {
-		img = image.NewRGBA(image.Rect(0, 0, width, height+4))
+// SetPixel turns the pixel at (x, y) on or off.  Pixels outside of the glyph
+// are ignored.
+func (c *Character) SetPixel(x, y int, on bool) {
+	if c.IsSpace || x < 0 || y < 0 || x >= c.Width() || y >= c.Height() {
+		return
+	}
+
+	idx, mask := c.glyphIndex(x, y)
+	if on {
+		c.glyph[idx] |= mask
 	} else {
-		img = image.NewRGBA(image.Rect(0, 0, width, height))
+		c.glyph[idx] &^= mask
 	}
+	c.invalidate()
+}
 
-	if c.IsSpace {
-		return img
+// InkBounds returns the bounding box of the set pixels in the glyph.  The
+// rectangle is empty for spacing characters and blank glyphs.
+func (c *Character) InkBounds() image.Rectangle {
+	ink := image.Rectangle{}
+	for y := 0; y < c.Height(); y++ {
+		for x := 0; x < c.Width(); x++ {
+			if c.Pixel(x, y) {
+				ink = ink.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
 	}
+	return ink
+}
 
-	x, y := 0, height
-	on := color.White
-	off := color.Black
+// Resize changes the dimensions of the glyph bitmap.  Existing pixels stay
+// anchored to the bottom left corner so the glyph doesn't move relative to
+// BlanksLeft.  The height is rounded up to a multiple of eight.
+func (c *Character) Resize(width, height int) error {
+	if height % 8 != 0 {
+		height += 8 - (height % 8)
+	}
 
-	c.GlyphCount = 0
-	for _, b := range c.glyph {
-		for i := 7; i > -1; i-- {
-			v := (b >> i) & 0x01
-			if v == 1 {
-				img.Set(height-y, height-x-1, on)
-			} else {
-				img.Set(height-y, height-x-1, off)
+	if width < 1 || width > MaxGlyphWidth {
+		return fmt.Errorf("glyph width %d out of range [1, %d]", width, MaxGlyphWidth)
+	}
+
+	if height < 8 || height > MaxGlyphHeight {
+		return fmt.Errorf("glyph height %d out of range [8, %d]", height, MaxGlyphHeight)
+	}
+
+	old := *c
+	c.IsSpace = false
+	c.BitmapSize = packBitmapSize(width, height)
+	c.glyph = make([]byte, glyphSize(width, height))
+
+	shift := height - old.Height()
+	for y := 0; y < old.Height(); y++ {
+		for x := 0; x < old.Width(); x++ {
+			if old.Pixel(x, y) {
+				c.SetPixel(x, y+shift, true)
 			}
-			x++
 		}
-		if x >= height {
-			y--
-			x = 0
+	}
+
+	c.invalidate()
+	return nil
+}
+
+// SetFromImage replaces the glyph with the given image.  A pixel is set if it
+// is mostly opaque and bright, which matches the output of Image() and
+// Mask().  baseline is the number of blank pixels between the bottom of the
+// character cell and the bottom of the image (ie, BlanksLeft).  If the image
+// height isn't a multiple of eight, blank rows are added to the top.
+func (c *Character) SetFromImage(img image.Image, baseline int) error {
+	b := img.Bounds()
+	err := c.Resize(b.Dx(), b.Dy())
+	if err != nil {
+		return err
+	}
+	c.clear()
+
+	err = c.SetBlanksLeft(baseline)
+	if err != nil {
+		return err
+	}
+
+	shift := c.Height() - b.Dy()
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			c.SetPixel(x, y+shift, pixelOn(img.At(b.Min.X+x, b.Min.Y+y)))
 		}
-		c.GlyphCount++
 	}
 
-	c.img = img
-	return img
+	return nil
+}
+
+// SetCellWidth sets the distance from the left of this glyph to the left of
+// the next one.
+func (c *Character) SetCellWidth(width int) error {
+	if width < 0 || width > 0xFFFF {
+		return fmt.Errorf("cell width %d out of range", width)
+	}
+	c.CellWidth = width
+	return nil
+}
+
+// SetBlanksLeft sets the number of blank pixels between the bottom of the
+// character cell and the bottom of the glyph.
+func (c *Character) SetBlanksLeft(blanks int) error {
+	if blanks < 0 || blanks > 0x7FFF {
+		return fmt.Errorf("blanks left %d out of range", blanks)
+	}
+	c.BlanksLeft = blanks
+	c.invalidate()
+	return nil
+}
+
+// SetIsSpace turns the character into a spacing character, discarding its
+// glyph, or turns a spacing character into a character with an empty 1x8
+// glyph.
+func (c *Character) SetIsSpace(space bool) {
+	if space == c.IsSpace {
+		return
+	}
+
+	if space {
+		c.IsSpace = true
+		c.glyph = nil
+		c.BitmapSize = packBitmapSize(1, 8)
+		c.invalidate()
+		return
+	}
+
+	c.IsSpace = false
+	c.BitmapSize = packBitmapSize(1, 8)
+	c.glyph = make([]byte, glyphSize(1, 8))
+	c.invalidate()
+}
+
+// clear turns off every pixel in the glyph.
+func (c *Character) clear() {
+	for i := range c.glyph {
+		c.glyph[i] = 0
+	}
+	c.invalidate()
+}
+
+// invalidate clears the cached images after the glyph has been modified.
+func (c *Character) invalidate() {
+	c.img = nil
+	c.mask = nil
+}
+
+func pixelOn(cl color.Color) bool {
+	r, g, b, a := cl.RGBA()
+	if a < 0x8000 {
+		return false
+	}
+
+	// Un-premultiply before comparing brightness.
+	lum := (299*r + 587*g + 114*b) / 1000
+	return lum*0xFFFF/a >= 0x8000
+}
+
+// Clone returns a deep copy of the character.
+func (c *Character) Clone() *Character {
+	n := *c
+	n.glyph = append([]byte(nil), c.glyph...)
+	n.invalidate()
+	return &n
 }
 
 func (c *Character) RawGlyph() []byte {
 	return c.glyph
 }
+
+// rawGlyph returns the glyph as it's stored in a file: padded to a whole word
+// with the bytes of each word swapped.
+func (c *Character) rawGlyph() []byte {
+	raw := make([]byte, glyphSize(c.Width(), c.Height()))
+	copy(raw, c.glyph)
+	swapWords(raw)
+	return raw
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"os"
 	"fmt"
+	"image"
 	"image/color"
 	"image/png"
 	"path/filepath"
@@ -16,6 +17,7 @@ import (
 
 type Arguments struct {
 	InputFile string `arg:"positional,required" help:"Xerox .FNT file"`
+	Format    string `arg:"--format" help:"Force the metadata format (9700 or 5Word) instead of detecting it."`
 
 	//OutputPrefix string `arg:"-p,--prefix"   help:"Prefix string for all output files."`
 	MetadataFile string `arg:"-m,--metadata" help:"File to write font metadata to."`
@@ -24,6 +26,7 @@ type Arguments struct {
 	SampleTextFile string `arg:"--sample-text-file" help:"File that contains sample text."`
 	SampleText     string `arg:"--sample-text"      help:"Sample text string."`
 	SampleOutput   string `arg:"--sample-output"    help:"Output filename for sample."`
+	SampleDPI      int    `arg:"--sample-dpi"       help:"Render the sample anti-aliased at this resolution (eg, 96) instead of at the device resolution."`
 
 	GlyphAscii string `arg:"--glyphs" help:"text file to write an ascii representation of the raw glyph data."`
 	Verbose bool `arg:"-v,--verbose"`
@@ -34,7 +37,12 @@ const (
 )
 
 func run(args *Arguments) error {
-	font, err := xf.LoadFontFromFile(args.InputFile)
+	format, err := xf.ParseFormat(args.Format)
+	if err != nil {
+		return err
+	}
+
+	font, err := xf.LoadFontFromFileWithOptions(args.InputFile, xf.LoadOptions{Format: format})
 	if err != nil {
 		return fmt.Errorf("Unable to load font: %w", err)
 	}
@@ -111,7 +119,7 @@ func run(args *Arguments) error {
 			sampleText = DefaultSampleText
 		}
 
-		err = drawText(args.SampleOutput, font, sampleText)
+		err = drawText(args.SampleOutput, font, sampleText, args.SampleDPI)
 		if err != nil {
 			return fmt.Errorf("Unable to write sample text: %w", err)
 		}
@@ -120,8 +128,13 @@ func run(args *Arguments) error {
 	return nil
 }
 
-func drawText(filename string, font *xf.Font, text string) error {
-	img := font.Render(color.Black, text)
+func drawText(filename string, font *xf.Font, text string, dpi int) error {
+	var img image.Image
+	if dpi > 0 {
+		img = font.RenderPreview(text, dpi)
+	} else {
+		img = font.Render(color.Black, text)
+	}
 
 	outfile, err := os.Create(filename)
 	if err != nil {
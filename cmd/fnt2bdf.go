@@ -12,6 +12,16 @@ import (
 type Arguments struct {
 	Input string `arg:"positional,required"`
 	Output string `arg:"positional"`
+
+	DPI int `arg:"--dpi" default:"300" help:"Resolution used to convert pixels to points."`
+	PointSize float64 `arg:"--point-size" help:"Point size of the font.  Defaults to the size in the font's name, or PixelHeight at --dpi."`
+	Foundry string `arg:"--foundry" help:"XLFD foundry name (default: Xerox)."`
+	Family string `arg:"--family" help:"XLFD family name (default: the font name)."`
+	Weight string `arg:"--weight" help:"XLFD weight name (default: Medium)."`
+	Slant string `arg:"--slant" help:"XLFD slant (default: R)."`
+	Registry string `arg:"--registry" help:"Character set registry (default: MICROSOFT, or ISO10646 with --charmap)."`
+	Encoding string `arg:"--encoding" help:"Character set encoding (default: CP1252, or 1 with --charmap)."`
+	Charmap string `arg:"--charmap" help:"Mapping table of the font's character codes to Unicode."`
 }
 
 func run(args *Arguments) error {
@@ -24,6 +34,13 @@ func run(args *Arguments) error {
 		return fmt.Errorf("No characters loaded!")
 	}
 
+	if args.Charmap != "" {
+		font.Encoding, err = xf.LoadEncodingFile(args.Charmap)
+		if err != nil {
+			return err
+		}
+	}
+
 	outfile := os.Stdout
 	if args.Output != "" {
 		file, err := os.Create(args.Output)
@@ -34,7 +51,16 @@ func run(args *Arguments) error {
 		outfile = file
 	}
 
-	fmt.Fprintln(outfile, font.BDF(10))
+	fmt.Fprintln(outfile, font.BDFWithOptions(xf.BDFOptions{
+		DPI: args.DPI,
+		PointSize: args.PointSize,
+		Foundry: args.Foundry,
+		Family: args.Family,
+		Weight: args.Weight,
+		Slant: args.Slant,
+		Registry: args.Registry,
+		Encoding: args.Encoding,
+	}))
 	return nil
 }
 
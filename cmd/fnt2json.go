@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"fmt"
+	"encoding/json"
+
+	"github.com/alexflint/go-arg"
+	xf "github.com/zorchenhimer/xeroxfont"
+)
+
+type Arguments struct {
+	Input string `arg:"positional,required" help:"Xerox .FNT file"`
+	Output string `arg:"positional" help:"JSON file to write.  Defaults to stdout."`
+	Format string `arg:"--format" help:"Force the metadata format (9700 or 5Word) instead of detecting it."`
+}
+
+func run(args *Arguments) error {
+	format, err := xf.ParseFormat(args.Format)
+	if err != nil {
+		return err
+	}
+
+	font, err := xf.LoadFontFromFileWithOptions(args.Input, xf.LoadOptions{Format: format})
+	if err != nil {
+		return fmt.Errorf("Unable to load font: %w", err)
+	}
+
+	if args.Output != "" {
+		return font.WriteJSONFile(args.Output)
+	}
+
+	raw, err := json.MarshalIndent(font, "", "    ")
+	if err != nil {
+		return fmt.Errorf("JSON marshal error: %w", err)
+	}
+	fmt.Println(string(raw))
+	return nil
+}
+
+func main() {
+	args := &Arguments{}
+	arg.MustParse(args)
+
+	err := run(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
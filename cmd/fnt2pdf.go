@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/alexflint/go-arg"
+	xf "github.com/zorchenhimer/xeroxfont"
+)
+
+type Arguments struct {
+	Input string `arg:"positional,required" help:"Xerox .FNT file"`
+	Output string `arg:"positional,required" help:"Output PDF file"`
+
+	TextFile string `arg:"-f,--text-file" help:"File with the text to set.  Use - for stdin."`
+	Text string `arg:"-t,--text" help:"Text to set."`
+
+	Page string `arg:"-p,--page" default:"letter" help:"Page size: letter or a4."`
+	Margin float64 `arg:"-m,--margin" default:"36" help:"Page margin in points."`
+	Wrap bool `arg:"-w,--wrap" help:"Wrap long lines at spaces."`
+}
+
+func run(args *Arguments) error {
+	if args.TextFile != "" && args.Text != "" {
+		return fmt.Errorf("--text-file and --text cannot be used at the same time")
+	}
+
+	text := args.Text
+	if args.TextFile == "-" {
+		raw, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("Unable to read text from stdin: %w", err)
+		}
+		text = string(raw)
+
+	} else if args.TextFile != "" {
+		raw, err := os.ReadFile(args.TextFile)
+		if err != nil {
+			return fmt.Errorf("Unable to read text from %s: %w", args.TextFile, err)
+		}
+		text = string(raw)
+	}
+
+	if text == "" {
+		return fmt.Errorf("No text given.  Use --text or --text-file.")
+	}
+
+	var opts xf.PDFOptions
+	switch strings.ToLower(args.Page) {
+	case "letter":
+		opts = xf.PageLetter
+	case "a4":
+		opts = xf.PageA4
+	default:
+		return fmt.Errorf("Unknown page size: %s", args.Page)
+	}
+	opts.Margin = args.Margin
+	opts.Wrap = args.Wrap
+
+	font, err := xf.LoadFontFromFile(args.Input)
+	if err != nil {
+		return fmt.Errorf("Unable to load font: %w", err)
+	}
+
+	return font.WritePDFFile(args.Output, text, opts)
+}
+
+func main() {
+	args := &Arguments{}
+	arg.MustParse(args)
+
+	err := run(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
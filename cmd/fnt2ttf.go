@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"fmt"
+
+	"github.com/alexflint/go-arg"
+	xf "github.com/zorchenhimer/xeroxfont"
+)
+
+type Arguments struct {
+	Input string `arg:"positional,required"`
+	Output string `arg:"positional,required"`
+
+	Mode string `arg:"--mode" default:"pixel" help:"Tracing mode: pixel or smooth"`
+	Family string `arg:"--family" help:"Family name.  Defaults to the family in the font name."`
+	Style string `arg:"--style" help:"Style name.  Defaults to Regular or Bold."`
+}
+
+func run(args *Arguments) error {
+	mode, err := xf.ParseTraceMode(args.Mode)
+	if err != nil {
+		return err
+	}
+
+	font, err := xf.LoadFontFromFile(args.Input)
+	if err != nil {
+		return fmt.Errorf("Unable to load font: %w", err)
+	}
+
+	if len(font.Characters) == 0 {
+		return fmt.Errorf("No characters loaded!")
+	}
+
+	return font.WriteTrueTypeFile(args.Output, xf.TrueTypeOptions{
+		Mode: mode,
+		Family: args.Family,
+		Style: args.Style,
+	})
+}
+
+func main() {
+	args := &Arguments{}
+	arg.MustParse(args)
+
+	err := run(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
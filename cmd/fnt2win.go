@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"fmt"
+	"strings"
+	"path/filepath"
+
+	"github.com/alexflint/go-arg"
+	xf "github.com/zorchenhimer/xeroxfont"
+)
+
+type Arguments struct {
+	Inputs []string `arg:"positional,required" help:"Xerox fonts.  Several fonts can be combined into one .FON file."`
+	Output string `arg:"-o,--output,required" help:"Output file.  Files ending in .fon are written as a .FON container, otherwise a single .FNT resource is written."`
+
+	Version int `arg:"--fnt-version" help:"Resource version, 2 or 3.  Defaults to 2 unless the font is too large."`
+	DPI int `arg:"--dpi" help:"Resolution of the font.  Defaults to 300."`
+	PointSize int `arg:"--point-size" help:"Point size of the font.  Defaults to the size in the font name."`
+	Face string `arg:"--face" help:"Face name.  Defaults to the family in the font name."`
+	Copyright string `arg:"--copyright"`
+	Module string `arg:"--module" help:"Module name for .FON files"`
+}
+
+func run(args *Arguments) error {
+	fonts := []*xf.Font{}
+	for _, input := range args.Inputs {
+		font, err := xf.LoadFontFromFile(input)
+		if err != nil {
+			return fmt.Errorf("Unable to load %s: %w", input, err)
+		}
+
+		if len(font.Characters) == 0 {
+			return fmt.Errorf("No characters loaded from %s", input)
+		}
+		fonts = append(fonts, font)
+	}
+
+	opts := xf.WinFNTOptions{
+		Version: args.Version,
+		DPI: args.DPI,
+		PointSize: args.PointSize,
+		Face: args.Face,
+		Copyright: args.Copyright,
+	}
+
+	if strings.EqualFold(filepath.Ext(args.Output), ".fon") {
+		return xf.WriteFONFile(args.Output, args.Module, fonts, opts)
+	}
+
+	if len(fonts) > 1 {
+		return fmt.Errorf("Only .FON files can hold more than one font")
+	}
+
+	data, err := fonts[0].WinFNT(opts)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(args.Output, data, 0644)
+}
+
+func main() {
+	args := &Arguments{}
+	arg.MustParse(args)
+
+	err := run(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"os"
+	"io"
+	"io/fs"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/alexflint/go-arg"
+	xf "github.com/zorchenhimer/xeroxfont"
+)
+
+type Arguments struct {
+	Inputs []string `arg:"positional,required" help:"Font files, directories, or glob patterns"`
+	Recursive bool `arg:"-r,--recursive" help:"Search directories recursively."`
+	Format string `arg:"--format" help:"Force the metadata format (9700 or 5Word) instead of detecting it."`
+
+	Output string `arg:"-o,--output" help:"Write to this file instead of stdout"`
+	CSV bool `arg:"--csv" help:"Write one table as CSV instead of every table as Markdown"`
+	Table string `arg:"-t,--table" help:"Table to write: fonts, values, or correlations.  Defaults to every table, or fonts for CSV."`
+}
+
+var tables = []string{"fonts", "values", "correlations"}
+
+func run(args *Arguments) error {
+	format, err := xf.ParseFormat(args.Format)
+	if err != nil {
+		return err
+	}
+
+	names := tables
+	if args.Table != "" {
+		names = nil
+		for _, t := range tables {
+			if strings.EqualFold(args.Table, t) {
+				names = []string{t}
+			}
+		}
+		if names == nil {
+			return fmt.Errorf("Unknown table: %q", args.Table)
+		}
+	} else if args.CSV {
+		names = tables[:1]
+	}
+
+	files, err := expandInputs(args.Inputs, args.Recursive)
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		return fmt.Errorf("No font files found")
+	}
+
+	corpus := &xf.Corpus{}
+	for _, file := range files {
+		err = corpus.AddFile(file, format)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Skipping %s: %s\n", file, err)
+		}
+	}
+
+	if len(corpus.Samples) == 0 {
+		return fmt.Errorf("None of the %d files could be read", len(files))
+	}
+
+	var w io.Writer = os.Stdout
+	if args.Output != "" {
+		file, err := os.Create(args.Output)
+		if err != nil {
+			return fmt.Errorf("Unable to create output file: %w", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	for i, name := range names {
+		var rows [][]string
+		switch name {
+		case "fonts":
+			rows = corpus.FontTable()
+		case "values":
+			rows = corpus.ValueTable()
+		case "correlations":
+			rows = corpus.CorrelationTable()
+		}
+
+		if args.CSV {
+			err = xf.WriteCSV(w, rows)
+			if err != nil {
+				return fmt.Errorf("Unable to write CSV: %w", err)
+			}
+			continue
+		}
+
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "## %s%s\n\n", strings.ToUpper(name[:1]), name[1:])
+		err = xf.WriteMarkdownTable(w, rows)
+		if err != nil {
+			return fmt.Errorf("Unable to write table: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// expandInputs turns the list of inputs into a list of files.  Globs are
+// expanded and directories are searched for .FNT files.
+func expandInputs(inputs []string, recursive bool) ([]string, error) {
+	files := []string{}
+	for _, input := range inputs {
+		matches, err := filepath.Glob(input)
+		if err != nil {
+			return nil, fmt.Errorf("Bad pattern %q: %w", input, err)
+		}
+
+		// Not a glob, or nothing matched.  Let loading report the error.
+		if matches == nil {
+			matches = []string{input}
+		}
+
+		for _, m := range matches {
+			st, err := os.Stat(m)
+			if err != nil || !st.IsDir() {
+				files = append(files, m)
+				continue
+			}
+
+			found, err := findFonts(m, recursive)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, found...)
+		}
+	}
+
+	return files, nil
+}
+
+func findFonts(dir string, recursive bool) ([]string, error) {
+	files := []string{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if path != dir && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if strings.EqualFold(filepath.Ext(path), ".fnt") {
+			files = append(files, path)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("Error searching %s: %w", dir, err)
+	}
+	return files, nil
+}
+
+func main() {
+	args := &Arguments{}
+	arg.MustParse(args)
+
+	err := run(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"os"
+	"io"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alexflint/go-arg"
+	xf "github.com/zorchenhimer/xeroxfont"
+)
+
+type Arguments struct {
+	Input string `arg:"positional,required"`
+	Text []string `arg:"positional" help:"Text to render.  Use - to read it from stdin."`
+
+	Glyphs string `arg:"-g,--glyphs" help:"Show these characters' glyphs at full size"`
+	All bool `arg:"-a,--all" help:"Show every glyph in the font"`
+	DPI int `arg:"--dpi" default:"100" help:"Resolution to render text at"`
+	Braille bool `arg:"-b,--braille" help:"Use braille characters instead of half blocks"`
+	Color bool `arg:"-c,--color" help:"Use 24-bit ANSI color to show gray levels"`
+	Charmap string `arg:"--charmap" help:"Mapping table of the font's character codes to Unicode (default: CP1252)"`
+	Grid bool `arg:"--grid" help:"Lay text out in FixedWidth cells, for fixed pitch fonts"`
+}
+
+const DefaultSampleText string = "The quick brown fox jumps over the lazy dog."
+
+func run(args *Arguments) error {
+	font, err := xf.LoadFontFromFile(args.Input)
+	if err != nil {
+		return fmt.Errorf("Unable to load font: %w", err)
+	}
+
+	if len(font.Characters) == 0 {
+		return fmt.Errorf("No characters loaded!")
+	}
+
+	if args.Charmap != "" {
+		font.Encoding, err = xf.LoadEncodingFile(args.Charmap)
+		if err != nil {
+			return err
+		}
+	}
+
+	if args.Grid {
+		if !font.IsFixed() {
+			return fmt.Errorf("Grid layout needs a fixed pitch font")
+		}
+		font.Grid = true
+
+		for _, m := range font.GridMismatches() {
+			fmt.Fprintf(os.Stderr, "Warning: character %s does not match fixed width %d\n", m, font.Header.FixedWidth)
+		}
+	}
+
+	opts := xf.TerminalOptions{Color: args.Color}
+	if args.Braille {
+		opts.Mode = xf.TermBraille
+	}
+
+	codes := []rune{}
+	if args.All {
+		for code := rune(0); code < rune(font.Header.MetaCount()); code++ {
+			if c, ok := font.Characters[code]; ok && !c.IsSpace {
+				codes = append(codes, code)
+			}
+		}
+	}
+
+	for _, r := range args.Glyphs {
+		if code, ok := font.CodeFor(r); ok {
+			codes = append(codes, code)
+		} else {
+			return fmt.Errorf("No character for %q", r)
+		}
+	}
+
+	for _, code := range codes {
+		c, ok := font.Characters[code]
+		if !ok {
+			return fmt.Errorf("Character 0x%02X is not in the font", code)
+		}
+
+		u, ok := font.UnicodeFor(code)
+		if !ok {
+			u = utf8.RuneError
+		}
+
+		fmt.Printf("0x%02X %q cell %d bitmap %dx%d blanks %d\n",
+			code, u, c.CellWidth, c.Width(), c.Height(), c.BlanksLeft)
+		fmt.Print(xf.TerminalImage(c.Upright(font.Header.Orientation), opts))
+	}
+
+	text := strings.Join(args.Text, " ")
+	if text == "-" {
+		raw, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		text = strings.TrimRight(string(raw), "\n")
+	}
+
+	if text == "" && len(codes) == 0 {
+		text = DefaultSampleText
+	}
+
+	if text != "" {
+		out, err := font.TerminalText(text, args.DPI, opts)
+		if err != nil {
+			return err
+		}
+		fmt.Print(out)
+	}
+
+	return nil
+}
+
+func main() {
+	args := &Arguments{}
+	arg.MustParse(args)
+
+	err := run(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
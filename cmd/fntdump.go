@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"fmt"
+
+	"github.com/alexflint/go-arg"
+	xf "github.com/zorchenhimer/xeroxfont"
+)
+
+type Arguments struct {
+	Input string `arg:"positional,required" help:"Xerox .FNT file"`
+	Format string `arg:"--format" help:"Force the metadata format (9700 or 5Word) instead of detecting it."`
+
+	MaxLines int `arg:"-n,--max-lines" help:"Hex lines to show per region.  Zero shows everything."`
+	Bitmaps bool `arg:"-b,--bitmaps" help:"Draw each glyph's bitmap under its bytes"`
+	Color bool `arg:"-c,--color" help:"Highlight unknown bytes, gaps, and overlaps with ANSI colors"`
+	Flagged bool `arg:"-f,--flagged" help:"Only show unknown and unused fields, gaps, trailing bytes, and overlapping glyphs"`
+}
+
+func run(args *Arguments) error {
+	format, err := xf.ParseFormat(args.Format)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(args.Input)
+	if err != nil {
+		return fmt.Errorf("Unable to read font: %w", err)
+	}
+
+	layout, err := xf.ReadLayout(data, format)
+	if err != nil {
+		return fmt.Errorf("Unable to read font layout: %w", err)
+	}
+
+	if args.Flagged {
+		regions := []xf.Region{}
+		for _, r := range layout.Regions {
+			if r.Kind != xf.RegionField && r.Kind != xf.RegionGlyph || len(r.Overlaps) > 0 {
+				regions = append(regions, r)
+			}
+		}
+		layout.Regions = regions
+	}
+
+	return layout.WriteDump(os.Stdout, xf.DumpOptions{
+		MaxLines: args.MaxLines,
+		Bitmaps: args.Bitmaps,
+		Color: args.Color,
+	})
+}
+
+func main() {
+	args := &Arguments{}
+	arg.MustParse(args)
+
+	err := run(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"os"
+	"fmt"
+	"image"
+	"encoding/json"
+
+	_ "image/png"
+	_ "image/gif"
+	_ "image/jpeg"
+
+	"github.com/alexflint/go-arg"
+	xf "github.com/zorchenhimer/xeroxfont"
+)
+
+type Arguments struct {
+	Input string `arg:"positional,required" help:"Image of a text sample at the fonts' resolution"`
+	Library []string `arg:"-L,--library,required,separate" help:"Font directory to compare against"`
+
+	Count int `arg:"-n,--count" default:"5" help:"Number of candidates to show"`
+	Threshold uint8 `arg:"--threshold" default:"128" help:"Pixels darker than this are ink"`
+	Shift int `arg:"--shift" default:"1" help:"Pixels a glyph may be moved when comparing"`
+	JSON bool `arg:"--json,-j" help:"Print results as JSON"`
+}
+
+type result struct {
+	Name string
+	Score float64
+	Matched int
+	Glyphs int
+	Text string
+}
+
+func run(args *Arguments) error {
+	file, err := os.Open(args.Input)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return fmt.Errorf("Unable to decode %s: %w", args.Input, err)
+	}
+
+	lib, err := xf.LoadFontLibrary(args.Library...)
+	if err != nil {
+		return err
+	}
+
+	matches, err := xf.Identify(img, lib, xf.IdentifyOptions{
+		Threshold: args.Threshold,
+		MaxShift: args.Shift,
+	})
+	if err != nil {
+		return err
+	}
+
+	if args.Count > 0 && len(matches) > args.Count {
+		matches = matches[:args.Count]
+	}
+
+	if args.JSON {
+		results := []result{}
+		for _, m := range matches {
+			results = append(results, result{m.Name, m.Score, m.Matched, m.Glyphs, m.Text})
+		}
+
+		raw, err := json.MarshalIndent(results, "", "    ")
+		if err != nil {
+			return fmt.Errorf("JSON marshal error: %w", err)
+		}
+		fmt.Println(string(raw))
+		return nil
+	}
+
+	fmt.Printf("%-4s %-10s %-6s %-9s %s\n", "RANK", "FONT", "SCORE", "MATCHED", "TEXT")
+	for i, m := range matches {
+		fmt.Printf("%-4d %-10s %.3f  %4d/%-4d %s\n", i+1, m.Name, m.Score, m.Matched, m.Glyphs, m.Text)
+	}
+	return nil
+}
+
+func main() {
+	args := &Arguments{}
+	arg.MustParse(args)
+
+	err := run(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"os"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"encoding/json"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/alexflint/go-arg"
+	xf "github.com/zorchenhimer/xeroxfont"
+)
+
+type Arguments struct {
+	Inputs []string `arg:"positional,required" help:"Font files, directories, or glob patterns"`
+	Recursive bool `arg:"-r,--recursive" help:"Search directories recursively."`
+	JSON bool `arg:"-j,--json" help:"Print results as JSON instead of a table."`
+	Format string `arg:"--format" help:"Force the metadata format (9700 or 5Word) instead of detecting it."`
+}
+
+type fileInfo struct {
+	File string
+	Info *xf.ProbeResult `json:",omitempty"`
+	Error string `json:",omitempty"`
+}
+
+func run(args *Arguments) error {
+	format, err := xf.ParseFormat(args.Format)
+	if err != nil {
+		return err
+	}
+
+	files, err := expandInputs(args.Inputs, args.Recursive)
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		return fmt.Errorf("No font files found")
+	}
+
+	results := []fileInfo{}
+	failed := 0
+	for _, file := range files {
+		info, err := xf.ProbeFileWithOptions(file, xf.LoadOptions{Format: format})
+		if err != nil {
+			results = append(results, fileInfo{File: file, Error: err.Error()})
+			failed++
+			continue
+		}
+		results = append(results, fileInfo{File: file, Info: info})
+	}
+
+	if args.JSON {
+		raw, err := json.MarshalIndent(results, "", "    ")
+		if err != nil {
+			return fmt.Errorf("JSON marshal error: %w", err)
+		}
+		fmt.Println(string(raw))
+
+	} else {
+		writeTable(results)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files could not be read", failed, len(files))
+	}
+	return nil
+}
+
+func writeTable(results []fileInfo) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	defer tw.Flush()
+
+	fmt.Fprintln(tw, "FILE\tFORMAT\tCONF\tEXTRA\tORIENTATION\tTYPE\tHEIGHT\tNAME\tREV\tLIBRARY\tGLYPHS")
+	for _, r := range results {
+		if r.Info == nil {
+			fmt.Fprintf(tw, "%s\terror: %s\n", r.File, r.Error)
+			continue
+		}
+
+		i := r.Info
+		fmt.Fprintf(tw, "%s\t%s\t%.2f\t%t\t%s\t%c\t%d\t%s\t%s\t%s\t%d\n",
+			r.File,
+			i.Format,
+			i.Confidence,
+			i.HasExtraHeader,
+			i.Orientation,
+			i.FontType,
+			i.PixelHeight,
+			i.Name,
+			i.Revision,
+			i.Library,
+			i.GlyphCount,
+		)
+	}
+}
+
+// expandInputs turns the list of inputs into a list of files.  Globs are
+// expanded and directories are searched for .FNT files.
+func expandInputs(inputs []string, recursive bool) ([]string, error) {
+	files := []string{}
+	for _, input := range inputs {
+		matches, err := filepath.Glob(input)
+		if err != nil {
+			return nil, fmt.Errorf("Bad pattern %q: %w", input, err)
+		}
+
+		// Not a glob, or nothing matched.  Let the probe report the error.
+		if matches == nil {
+			matches = []string{input}
+		}
+
+		for _, m := range matches {
+			st, err := os.Stat(m)
+			if err != nil || !st.IsDir() {
+				files = append(files, m)
+				continue
+			}
+
+			found, err := findFonts(m, recursive)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, found...)
+		}
+	}
+
+	return files, nil
+}
+
+func findFonts(dir string, recursive bool) ([]string, error) {
+	files := []string{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if path != dir && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if strings.EqualFold(filepath.Ext(path), ".fnt") {
+			files = append(files, path)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("Error searching %s: %w", dir, err)
+	}
+	return files, nil
+}
+
+func main() {
+	args := &Arguments{}
+	arg.MustParse(args)
+
+	err := run(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"fmt"
+	"image/png"
+
+	"github.com/alexflint/go-arg"
+	xf "github.com/zorchenhimer/xeroxfont"
+)
+
+type Arguments struct {
+	Job string `arg:"positional,required" help:"JSON job file"`
+	Output string `arg:"-o,--output,required" help:"PNG file to write"`
+	Library []string `arg:"-L,--library,separate" help:"Extra font directory.  These are searched after the job's own library."`
+	List bool `arg:"--list" help:"List the fonts in the library and exit"`
+}
+
+func run(args *Arguments) error {
+	job, err := xf.LoadJob(args.Job)
+	if err != nil {
+		return err
+	}
+
+	lib, err := job.LoadLibrary()
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range args.Library {
+		err = lib.LoadDir(dir)
+		if err != nil {
+			return err
+		}
+	}
+
+	if args.List {
+		for _, name := range lib.Names() {
+			fmt.Println(name)
+		}
+		return nil
+	}
+
+	page, err := job.Render(lib)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(args.Output)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	err = png.Encode(file, page)
+	if err != nil {
+		return fmt.Errorf("Unable to encode %s: %w", args.Output, err)
+	}
+	return file.Close()
+}
+
+func main() {
+	args := &Arguments{}
+	arg.MustParse(args)
+
+	err := run(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
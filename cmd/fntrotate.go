@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/alexflint/go-arg"
+	xf "github.com/zorchenhimer/xeroxfont"
+)
+
+type Arguments struct {
+	Input string `arg:"positional,required" help:"Portrait .FNT file"`
+	OutputDir string `arg:"-d,--output-dir" help:"Directory to write the new fonts to.  Defaults to the input's directory."`
+	Orientations string `arg:"-o,--orientations" default:"LIJ" help:"Orientations to generate: L (landscape), I (inverted portrait), J (inverted landscape)."`
+	Verify bool `arg:"--verify" help:"Compare with the fonts already in the output directory instead of writing them.  Fails if any ink differs."`
+}
+
+func run(args *Arguments) error {
+	font, err := xf.LoadFontFromFile(args.Input)
+	if err != nil {
+		return fmt.Errorf("Unable to load font: %w", err)
+	}
+
+	if args.OutputDir == "" {
+		args.OutputDir = filepath.Dir(args.Input)
+	}
+
+	failed := 0
+	for _, c := range strings.ToUpper(args.Orientations) {
+		o, err := xf.ParseOrientation(string(c))
+		if err != nil {
+			return err
+		}
+
+		rotated, err := font.Rotate(o)
+		if err != nil {
+			return fmt.Errorf("Unable to rotate font: %w", err)
+		}
+
+		filename := filepath.Join(args.OutputDir, outputName(args.Input, o))
+		if args.Verify {
+			inkDiffs, err := verify(rotated, filename)
+			if err != nil {
+				return err
+			}
+			failed += inkDiffs
+			continue
+		}
+
+		err = rotated.WriteFile(filename)
+		if err != nil {
+			return err
+		}
+		fmt.Println(filename)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d characters have different ink", failed)
+	}
+	return nil
+}
+
+// verify compares a rotated font with an existing file and prints the
+// differences.  It returns the number of characters whose ink differs.
+func verify(rotated *xf.Font, filename string) (int, error) {
+	existing, err := xf.LoadFontFromFile(filename)
+	if err != nil {
+		return 0, fmt.Errorf("Unable to load %s: %w", filename, err)
+	}
+
+	inkDiffs := 0
+	diffs := rotated.CompareGlyphs(existing)
+	for _, d := range diffs {
+		if d.Ink {
+			inkDiffs++
+		}
+		fmt.Printf("%s: %s\n", filename, d)
+	}
+	fmt.Printf("%s: %d differences, %d with different ink\n", filename, len(diffs), inkDiffs)
+	return inkDiffs, nil
+}
+
+// outputName replaces the orientation letter at the end of the input's
+// filename, eg BS10NP.FNT becomes BS10NL.FNT.  Filenames without one get the
+// letter appended.
+func outputName(input string, o xf.Orientation) string {
+	ext := filepath.Ext(input)
+	base := strings.TrimSuffix(filepath.Base(input), ext)
+
+	if strings.HasSuffix(base, "P") {
+		return strings.TrimSuffix(base, "P") + string(rune(o)) + ext
+	} else if strings.HasSuffix(base, "p") {
+		return strings.TrimSuffix(base, "p") + strings.ToLower(string(rune(o))) + ext
+	}
+	return base + "_" + string(rune(o)) + ext
+}
+
+func main() {
+	args := &Arguments{}
+	arg.MustParse(args)
+
+	err := run(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"fmt"
+
+	"github.com/alexflint/go-arg"
+	xf "github.com/zorchenhimer/xeroxfont"
+)
+
+type Arguments struct {
+	Input string `arg:"positional,required" help:"Portrait .FNT file"`
+	Output string `arg:"positional,required" help:"Output .FNT file"`
+
+	Bold int `arg:"-b,--bold" help:"Embolden glyphs by this many pixels."`
+	Oblique float64 `arg:"-o,--oblique" help:"Shear glyphs by this many pixels per pixel above the baseline (eg, 0.2)."`
+	Name string `arg:"-n,--name" help:"New font name (up to six characters).  Defaults to the input's name with a bold weight if --bold is given."`
+}
+
+func run(args *Arguments) error {
+	if args.Bold == 0 && args.Oblique == 0 {
+		return fmt.Errorf("Nothing to do: use --bold and/or --oblique")
+	}
+
+	font, err := xf.LoadFontFromFile(args.Input)
+	if err != nil {
+		return fmt.Errorf("Unable to load font: %w", err)
+	}
+
+	if args.Bold != 0 {
+		font, err = font.Embolden(args.Bold)
+		if err != nil {
+			return fmt.Errorf("Unable to embolden font: %w", err)
+		}
+
+		if id, err := font.FontID(); err == nil && args.Name == "" {
+			id.Weight = xf.WeightBold
+			err = font.SetFontID(id)
+			if err != nil {
+				return fmt.Errorf("Unable to name bold font; use --name: %w", err)
+			}
+		}
+	}
+
+	if args.Oblique != 0 {
+		font, err = font.Oblique(args.Oblique)
+		if err != nil {
+			return fmt.Errorf("Unable to slant font: %w", err)
+		}
+	}
+
+	if args.Name != "" {
+		if len(args.Name) > len(font.Header.FontName) {
+			return fmt.Errorf("Font name %q is longer than %d characters", args.Name, len(font.Header.FontName))
+		}
+		copy(font.Header.FontName[:], fmt.Sprintf("%-6s", args.Name))
+	}
+
+	return font.WriteFile(args.Output)
+}
+
+func main() {
+	args := &Arguments{}
+	arg.MustParse(args)
+
+	err := run(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/alexflint/go-arg"
+	xf "github.com/zorchenhimer/xeroxfont"
+)
+
+type Arguments struct {
+	Input string `arg:"positional,required" help:"Xerox .FNT file"`
+	TextFile string `arg:"positional,required" help:"Text file with the characters to keep."`
+	Output string `arg:"positional,required" help:"Output .FNT file"`
+
+	Drop bool `arg:"--drop" help:"Remove unused characters and lower LastCharacter instead of turning them into spacing characters."`
+	Bytes bool `arg:"--bytes" help:"Treat each byte of the text file as a character code instead of decoding it as UTF-8."`
+	Verbose bool `arg:"-v,--verbose" help:"Print the characters that were kept."`
+}
+
+func run(args *Arguments) error {
+	font, err := xf.LoadFontFromFile(args.Input)
+	if err != nil {
+		return fmt.Errorf("Unable to load font: %w", err)
+	}
+
+	raw, err := os.ReadFile(args.TextFile)
+	if err != nil {
+		return fmt.Errorf("Unable to read text: %w", err)
+	}
+
+	var runes []rune
+	if args.Bytes {
+		for _, b := range raw {
+			runes = append(runes, rune(b))
+		}
+	} else {
+		if !utf8.Valid(raw) {
+			return fmt.Errorf("%s is not valid UTF-8; try --bytes", args.TextFile)
+		}
+		runes = []rune(string(raw))
+	}
+
+	keep := []rune{}
+	seen := make(map[rune]bool)
+	for _, r := range runes {
+		if seen[r] {
+			continue
+		}
+		seen[r] = true
+
+		// Text goes through the font's character set like it does when
+		// it's drawn.  Bytes are already character codes.
+		code, ok := r, true
+		if args.Bytes {
+			_, ok = font.Characters[code]
+		} else {
+			code, ok = font.CodeFor(r)
+		}
+
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Warning: %q (0x%02X) is not in the font\n", r, r)
+			continue
+		}
+		keep = append(keep, code)
+	}
+
+	mode := xf.SubsetSpace
+	if args.Drop {
+		mode = xf.SubsetDrop
+	}
+
+	subset := font.Subset(keep, mode)
+	if args.Verbose {
+		fmt.Printf("Kept %d characters: %X\n", len(keep), keep)
+	}
+
+	return subset.WriteFile(args.Output)
+}
+
+func main() {
+	args := &Arguments{}
+	arg.MustParse(args)
+
+	err := run(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
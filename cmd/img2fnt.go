@@ -0,0 +1,160 @@
+package main
+
+import (
+	"os"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"path/filepath"
+	"encoding/json"
+	"regexp"
+	"strconv"
+
+	"github.com/alexflint/go-arg"
+	xf "github.com/zorchenhimer/xeroxfont"
+)
+
+type Arguments struct {
+	ImageDir string `arg:"positional,required" help:"Directory of glyph images as written by debug --image-dir."`
+	Output string `arg:"positional,required" help:"Output .FNT file."`
+
+	MetadataFile string `arg:"-m,--metadata" help:"JSON metadata as written by debug --metadata.  Defaults to metadata.json in the image directory."`
+	Format string `arg:"--format" help:"Metadata format to write (9700 or 5Word).  Overrides the metadata file."`
+	Threshold uint8 `arg:"-t,--threshold" default:"128" help:"Brightness at or above which a pixel is set."`
+	Invert bool `arg:"-i,--invert" help:"Images are dark glyphs on a light background."`
+}
+
+// Matches filenames like 065_0x41.png.  Only the decimal part is used.
+var reGlyphFile = regexp.MustCompile(`^(\d+)_0x[0-9A-Fa-f]+\.png$`)
+
+func run(args *Arguments) error {
+	if args.MetadataFile == "" {
+		args.MetadataFile = filepath.Join(args.ImageDir, "metadata.json")
+	}
+
+	raw, err := os.ReadFile(args.MetadataFile)
+	if err != nil {
+		return fmt.Errorf("Unable to read metadata: %w", err)
+	}
+
+	font := &xf.Font{}
+	err = json.Unmarshal(raw, font)
+	if err != nil {
+		return fmt.Errorf("Unable to parse metadata from %s: %w", args.MetadataFile, err)
+	}
+
+	if font.Header == nil {
+		return fmt.Errorf("Metadata is missing the font header")
+	}
+
+	if font.Characters == nil {
+		font.Characters = make(map[rune]*xf.Character)
+	}
+
+	if args.Format != "" {
+		font.Format, err = xf.ParseFormat(args.Format)
+		if err != nil {
+			return err
+		}
+	}
+
+	entries, err := os.ReadDir(args.ImageDir)
+	if err != nil {
+		return fmt.Errorf("Unable to read image directory: %w", err)
+	}
+
+	found := make(map[rune]bool)
+	for _, entry := range entries {
+		match := reGlyphFile.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		id, err := strconv.Atoi(match[1])
+		if err != nil {
+			return fmt.Errorf("Bad glyph filename %s: %w", entry.Name(), err)
+		}
+		r := rune(id)
+
+		img, err := readImage(filepath.Join(args.ImageDir, entry.Name()))
+		if err != nil {
+			return err
+		}
+
+		chr, ok := font.Characters[r]
+		if !ok {
+			// No metrics for this one.  Sit it on the bottom of the cell
+			// and space it by the width of the image.
+			chr = &xf.Character{Value: r, CellWidth: img.Bounds().Dx()}
+			font.Characters[r] = chr
+		}
+
+		err = chr.SetFromImage(threshold(img, args.Threshold, args.Invert), chr.BlanksLeft)
+		if err != nil {
+			return fmt.Errorf("Unable to set glyph for 0x%02X from %s: %w", r, entry.Name(), err)
+		}
+		found[r] = true
+
+		if int(r) > int(font.Header.LastCharacter) {
+			font.Header.LastCharacter = uint16(r)
+		}
+	}
+
+	for r, chr := range font.Characters {
+		if !chr.IsSpace && !found[r] {
+			fmt.Fprintf(os.Stderr, "Warning: no image for character 0x%02X; it will be blank\n", r)
+		}
+	}
+
+	return font.WriteFile(args.Output)
+}
+
+func readImage(filename string) (image.Image, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to decode %s: %w", filename, err)
+	}
+	return img, nil
+}
+
+// threshold converts an image to a 1-bit image with white glyph pixels on a
+// black background.  Transparent pixels are always off.
+func threshold(img image.Image, level uint8, invert bool) image.Image {
+	b := img.Bounds()
+	out := image.NewGray(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			cl := img.At(x, y)
+			_, _, _, a := cl.RGBA()
+			gray := color.GrayModel.Convert(cl).(color.Gray)
+
+			on := gray.Y >= level
+			if invert {
+				on = !on
+			}
+
+			if on && a >= 0x8000 {
+				out.SetGray(x, y, color.Gray{Y: 0xFF})
+			}
+		}
+	}
+	return out
+}
+
+func main() {
+	args := &Arguments{}
+	arg.MustParse(args)
+
+	err := run(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
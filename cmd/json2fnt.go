@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"fmt"
+
+	"github.com/alexflint/go-arg"
+	xf "github.com/zorchenhimer/xeroxfont"
+)
+
+type Arguments struct {
+	Input string `arg:"positional,required" help:"JSON font as written by fnt2json"`
+	Output string `arg:"positional,required" help:"Output .FNT file"`
+	Format string `arg:"--format" help:"Metadata format to write (9700 or 5Word).  Overrides the JSON."`
+}
+
+func run(args *Arguments) error {
+	font, err := xf.LoadFontJSONFile(args.Input)
+	if err != nil {
+		return fmt.Errorf("Unable to load font: %w", err)
+	}
+
+	if args.Format != "" {
+		font.Format, err = xf.ParseFormat(args.Format)
+		if err != nil {
+			return err
+		}
+	}
+
+	return font.WriteFile(args.Output)
+}
+
+func main() {
+	args := &Arguments{}
+	arg.MustParse(args)
+
+	err := run(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"os"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+	"strconv"
+	"path/filepath"
+
+	"github.com/alexflint/go-arg"
+	xf "github.com/zorchenhimer/xeroxfont"
+)
+
+type Arguments struct {
+	Input string `arg:"positional,required" help:"Line mode print file"`
+	Output string `arg:"-o,--output,required" help:"Output file.  Files ending in .tif or .tiff get every page, otherwise one PNG is written per page with the page number added to the name."`
+	Fonts []string `arg:"-f,--font,required,separate" help:"Font to print with.  Give more than once to select fonts by index."`
+
+	Carriage string `arg:"-c,--carriage-control" default:"asa" help:"Carriage control: none, asa, or machine"`
+	TRC bool `arg:"--trc" help:"Records have a font index after the carriage control"`
+	Grid bool `arg:"--grid" help:"Print fixed pitch fonts in FixedWidth columns, leaving a blank column for characters the font doesn't have"`
+	Channels []string `arg:"--channel,separate" help:"Forms control channel line, like 12=60"`
+
+	Page string `arg:"-p,--page" default:"letter" help:"Page size: letter or a4."`
+	Margin float64 `arg:"-m,--margin" default:"36" help:"Page margin in points."`
+	LinesPerPage int `arg:"-l,--lines-per-page" help:"Lines on each page.  Defaults to what fits with the first font."`
+}
+
+func run(args *Arguments) error {
+	fonts := []*xf.Font{}
+	for _, name := range args.Fonts {
+		font, err := xf.LoadFontFromFile(name)
+		if err != nil {
+			return fmt.Errorf("Unable to load %s: %w", name, err)
+		}
+		font.Grid = args.Grid
+		fonts = append(fonts, font)
+	}
+
+	cc, err := xf.ParseCarriageControl(args.Carriage)
+	if err != nil {
+		return err
+	}
+
+	var opts xf.LineModeOptions
+	switch strings.ToLower(args.Page) {
+	case "letter":
+		opts.PageWidth, opts.PageHeight = xf.PageLetter.PageWidth, xf.PageLetter.PageHeight
+	case "a4":
+		opts.PageWidth, opts.PageHeight = xf.PageA4.PageWidth, xf.PageA4.PageHeight
+	default:
+		return fmt.Errorf("Unknown page size: %s", args.Page)
+	}
+
+	opts.Margin = args.Margin
+	opts.LinesPerPage = args.LinesPerPage
+	opts.CarriageControl = cc
+	opts.TRC = args.TRC
+	opts.Channels = map[int]int{}
+
+	for _, ch := range args.Channels {
+		channel, line, found := strings.Cut(ch, "=")
+		c, err1 := strconv.Atoi(channel)
+		l, err2 := strconv.Atoi(line)
+		if !found || err1 != nil || err2 != nil || c < 1 || c > 12 {
+			return fmt.Errorf("Invalid channel: %q", ch)
+		}
+		opts.Channels[c] = l
+	}
+
+	input, err := os.Open(args.Input)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	ext := strings.ToLower(filepath.Ext(args.Output))
+	if ext == ".tif" || ext == ".tiff" {
+		file, err := os.Create(args.Output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		tiff := xf.NewTIFFWriter(file, xf.DeviceDPI)
+		err = xf.PrintLineData(input, fonts, opts, func(page int, img *image.Gray) error {
+			return tiff.WritePage(img)
+		})
+		if err != nil {
+			return err
+		}
+
+		err = tiff.Close()
+		if err != nil {
+			return err
+		}
+		return file.Close()
+	}
+
+	base := strings.TrimSuffix(args.Output, filepath.Ext(args.Output))
+	return xf.PrintLineData(input, fonts, opts, func(page int, img *image.Gray) error {
+		file, err := os.Create(fmt.Sprintf("%s-%03d.png", base, page))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		err = png.Encode(file, img)
+		if err != nil {
+			return err
+		}
+		return file.Close()
+	})
+}
+
+func main() {
+	args := &Arguments{}
+	arg.MustParse(args)
+
+	err := run(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,164 @@
+package xeroxfont
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Detection is the result of scoring both metadata table layouts against a
+// font file.
+type Detection struct {
+	Format Format
+
+	// Difference between the winning and losing scores.  Zero means both
+	// layouts were equally plausible.
+	Confidence float64
+
+	Score9700  float64
+	Score5Word float64
+}
+
+func (d Detection) String() string {
+	return fmt.Sprintf("%s (confidence %.2f; 9700: %.2f 5Word: %.2f)",
+		d.Format, d.Confidence, d.Score9700, d.Score5Word)
+}
+
+// LoadOptions changes how a font is read.  The zero value auto-detects
+// everything.
+type LoadOptions struct {
+	// Use this metadata table format instead of detecting it.
+	Format Format
+}
+
+// ParseFormat returns the Format for a name as returned by Format.String().
+// Case is ignored.
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "9700":
+		return Format9700, nil
+	case "5word":
+		return Format5Word, nil
+	case "", "auto", "unknown":
+		return FormatUnknown, nil
+	}
+	return FormatUnknown, fmt.Errorf("Unknown format: %q", name)
+}
+
+// DetectFormat figures out if a font uses the 9700 or 5Word metadata table.
+// Both layouts are scored against the file and the most plausible one wins.
+func DetectFormat(reader io.ReadSeeker) (*Detection, error) {
+	extra, header, err := readHeaders(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	metaStart := int64(binary.Size(header) + 256)
+	if extra != nil {
+		metaStart += int64(binary.Size(extra))
+	}
+
+	return detectFormat(reader, header, metaStart)
+}
+
+func detectFormat(reader io.ReadSeeker, header *FontHeader, metaStart int64) (*Detection, error) {
+	fileSize, err := reader.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, fmt.Errorf("Error finding file size: %w", err)
+	}
+
+	_, err = reader.Seek(metaStart, io.SeekStart)
+	if err != nil {
+		return nil, fmt.Errorf("Error seeking to metadata table: %w", err)
+	}
+
+	// Read enough for the larger of the two tables.  A short read is fine,
+	// the layout that doesn't fit will just score zero.
+	count := header.MetaCount()
+	raw := make([]byte, count*binary.Size(CharacterMeta5Word{}))
+	n, err := io.ReadFull(reader, raw)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("Error reading metadata table: %w", err)
+	}
+	raw = raw[:n]
+
+	det := &Detection{}
+	if meta, err := MetaFrom9700(bytes.NewReader(raw), count); err == nil {
+		glyphStart := metaStart + int64(count*binary.Size(CharacterMeta9700{}))
+		det.Score9700 = scoreMeta(meta, header, fileSize-glyphStart)
+		if header.Is9700() {
+			det.Score9700 += 0.1
+		}
+	}
+
+	if meta, err := MetaFrom5Word(bytes.NewReader(raw), count); err == nil {
+		glyphStart := metaStart + int64(count*binary.Size(CharacterMeta5Word{}))
+		det.Score5Word = scoreMeta(meta, header, fileSize-glyphStart)
+		if header.Unknown5Word != 0 {
+			det.Score5Word += 0.1
+		}
+	}
+
+	if det.Score9700 == 0 && det.Score5Word == 0 {
+		det.Format = FormatUnknown
+	} else if det.Score9700 >= det.Score5Word {
+		det.Format = Format9700
+		det.Confidence = det.Score9700 - det.Score5Word
+	} else {
+		det.Format = Format5Word
+		det.Confidence = det.Score5Word - det.Score9700
+	}
+
+	return det, nil
+}
+
+// scoreMeta returns a value between 0.0 and 0.9 describing how plausible a
+// parsed metadata table is.  glyphAreaSize is the number of bytes in the file
+// after the metadata table.
+func scoreMeta(meta []CharacterMeta, header *FontHeader, glyphAreaSize int64) float64 {
+	if glyphAreaSize <= 0 {
+		return 0
+	}
+
+	maxMetric := 4 * int(header.PixelHeight)
+	if maxMetric < 256 {
+		maxMetric = 256
+	}
+
+	var glyphs, fits, ordered, plausible int
+	var prevEnd int64 = -1
+	for _, m := range meta {
+		c := &Character{BitmapSize: m.BitmapSize}
+
+		// Metrics no bigger than a few glyphs.
+		if m.BlanksLeft <= maxMetric && m.CellWidth <= maxMetric {
+			plausible++
+		}
+
+		if m.IsSpace() {
+			continue
+		}
+		glyphs++
+
+		start := int64(m.GlyphOffset) * 2
+		end := start + int64(c.Width()*(c.Height()/8))
+		if c.Width() > 0 && end <= glyphAreaSize {
+			fits++
+		}
+
+		if start >= prevEnd {
+			ordered++
+		}
+		prevEnd = end
+	}
+
+	if glyphs == 0 {
+		return 0
+	}
+
+	return 0.3*float64(fits)/float64(glyphs) +
+		0.3*float64(ordered)/float64(glyphs) +
+		0.3*float64(plausible)/float64(len(meta))
+}
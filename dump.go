@@ -0,0 +1,414 @@
+package xeroxfont
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type RegionKind int
+
+const (
+	// A header field or table entry with a known meaning.
+	RegionField RegionKind = iota
+
+	// A header field that is read but isn't understood yet.
+	RegionUnknown
+
+	// Header bytes that are skipped when reading.
+	RegionUnused
+
+	RegionGlyph
+
+	// Bytes in the glyph area that no character's bitmap covers.
+	RegionGap
+
+	// Bytes after the last glyph bitmap.
+	RegionTrailing
+)
+
+func (k RegionKind) String() string {
+	switch k {
+	case RegionField:
+		return "field"
+	case RegionUnknown:
+		return "unknown"
+	case RegionUnused:
+		return "unused"
+	case RegionGlyph:
+		return "glyph"
+	case RegionGap:
+		return "gap"
+	case RegionTrailing:
+		return "trailing"
+	}
+	return fmt.Sprintf("RegionKind(%d)", int(k))
+}
+
+// Region is a run of bytes in a font file.
+type Region struct {
+	Offset int
+	Size   int
+	Name   string
+	Kind   RegionKind
+
+	// Decoded value, or a description of the bytes.
+	Value string
+
+	// Characters whose glyph bitmaps share bytes with this one.
+	Overlaps []rune
+
+	// Character code of metadata and glyph regions.
+	Code rune
+
+	// Decoded glyph of glyph regions, unless the bitmap is cut short.
+	Char *Character
+}
+
+// Layout is every region of a font file in file order.  Overlapping glyphs
+// are ordered by their start offset.
+type Layout struct {
+	Format  Format
+	Data    []byte
+	Regions []Region
+
+	Extra  *ExtraHeader
+	Header *FontHeader
+	Meta   []CharacterMeta
+
+	GlyphStart int
+}
+
+// ReadLayout walks a font file's structure.  The format is detected if it's
+// FormatUnknown.  Glyph bitmaps that run past the end of the file are cut
+// short instead of returning an error, so broken files can still be looked
+// at.
+func ReadLayout(data []byte, format Format) (*Layout, error) {
+	reader := bytes.NewReader(data)
+	extra, header, err := readHeaders(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Layout{Format: format, Data: data, Extra: extra, Header: header}
+	offset := 0
+	if extra != nil {
+		offset = l.addStruct("ExtraHeader", offset, ExtraHeader{})
+	}
+	offset = l.addStruct("Header", offset, FontHeader{})
+	if len(data) < offset+256 {
+		return nil, fmt.Errorf("Error reading width table: file is too short")
+	}
+
+	for i := 0; i < 256; i += 16 {
+		widths := []string{}
+		for _, w := range data[offset:offset+16] {
+			widths = append(widths, fmt.Sprint(w))
+		}
+		l.Regions = append(l.Regions, Region{
+			Offset: offset,
+			Size:   16,
+			Name:   fmt.Sprintf("Widths[0x%02X-0x%02X]", i, i+15),
+			Value:  strings.Join(widths, " "),
+		})
+		offset += 16
+	}
+
+	if l.Format == FormatUnknown {
+		det, err := detectFormat(reader, header, int64(offset))
+		if err != nil {
+			return nil, fmt.Errorf("Error detecting format: %w", err)
+		}
+		if det.Format == FormatUnknown {
+			return nil, fmt.Errorf("Unable to detect metadata format")
+		}
+		l.Format = det.Format
+	}
+
+	_, err = reader.Seek(int64(offset), io.SeekStart)
+	if err != nil {
+		return nil, fmt.Errorf("Error seeking to metadata table: %w", err)
+	}
+
+	var meta []CharacterMeta
+	var entrySize int
+	switch l.Format {
+	case Format9700:
+		meta, err = MetaFrom9700(reader, header.MetaCount())
+		entrySize = binary.Size(CharacterMeta9700{})
+	case Format5Word:
+		meta, err = MetaFrom5Word(reader, header.MetaCount())
+		entrySize = binary.Size(CharacterMeta5Word{})
+	default:
+		return nil, fmt.Errorf("Unsupported format: %s", l.Format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing metadata: %w", err)
+	}
+
+	l.Meta = meta
+	l.GlyphStart = offset + len(meta)*entrySize
+	for id, m := range meta {
+		value := fmt.Sprintf("spacing, CellWidth %d", m.CellWidth)
+		if !m.IsSpace() {
+			c := &Character{BitmapSize: m.BitmapSize}
+			value = fmt.Sprintf("BlanksLeft %d, GlyphOffset %d (at $%06X), %dx%d, CellWidth %d",
+				m.BlanksLeft, m.GlyphOffset, m.Offset(int64(l.GlyphStart)), c.Width(), c.Height(), m.CellWidth)
+		}
+		if l.Format == Format5Word {
+			value += fmt.Sprintf(", Unknown $%04X", m.Unknown)
+		}
+
+		l.Regions = append(l.Regions, Region{
+			Offset: offset,
+			Size:   entrySize,
+			Name:   fmt.Sprintf("Meta[0x%02X]", id),
+			Value:  value,
+			Code:   rune(id),
+		})
+		offset += entrySize
+	}
+
+	l.addGlyphs(reader, meta)
+	return l, nil
+}
+
+// addStruct adds a region for each field of a header struct that starts at
+// offset and returns the offset after it.  Fields starting with "Reserved"
+// are unused and fields starting with "Unknown" are unknown.
+func (l *Layout) addStruct(prefix string, offset int, v any) int {
+	t := reflect.TypeOf(v)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		size := binary.Size(reflect.Zero(field.Type).Interface())
+		raw := l.Data[offset:offset+size]
+
+		r := Region{
+			Offset: offset,
+			Size:   size,
+			Name:   prefix + "." + field.Name,
+		}
+
+		switch {
+		case strings.HasPrefix(field.Name, "Reserved"):
+			r.Kind = RegionUnused
+			r.Value = describeBytes(raw)
+		case strings.HasPrefix(field.Name, "Unknown"):
+			r.Kind = RegionUnknown
+			r.Value = describeBytes(raw)
+		default:
+			val := reflect.New(field.Type)
+			binary.Read(bytes.NewReader(raw), binary.LittleEndian, val.Interface())
+			r.Value = decodeField(field.Name, val.Elem())
+		}
+
+		l.Regions = append(l.Regions, r)
+		offset += size
+	}
+	return offset
+}
+
+func decodeField(name string, v reflect.Value) string {
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		if v.Kind() == reflect.Uint8 {
+			return fmt.Sprintf("$%02X %s", v.Uint(), s)
+		}
+		return s.String()
+	}
+
+	switch v.Kind() {
+	case reflect.Uint8:
+		b := byte(v.Uint())
+		if name == "FontType" && (b == 'P' || b == 'F') {
+			return fontTypeName(b)
+		}
+		if b >= 0x20 && b < 0x7F {
+			return fmt.Sprintf("%d %q", b, b)
+		}
+		return fmt.Sprint(b)
+	case reflect.Uint16:
+		return fmt.Sprint(v.Uint())
+	case reflect.Int16:
+		return fmt.Sprint(v.Int())
+	case reflect.Array:
+		raw := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(raw), v)
+		return fmt.Sprintf("%q", raw)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// describeBytes summarizes bytes that aren't decoded.
+func describeBytes(raw []byte) string {
+	nonzero := 0
+	for _, b := range raw {
+		if b != 0 {
+			nonzero++
+		}
+	}
+
+	if nonzero == 0 {
+		return "all zero"
+	}
+	return fmt.Sprintf("%d non-zero", nonzero)
+}
+
+// addGlyphs adds a region for each glyph bitmap, the gaps between them, and
+// anything after the last one.
+func (l *Layout) addGlyphs(reader io.ReadSeeker, meta []CharacterMeta) {
+	glyphs := []Region{}
+	for id, m := range meta {
+		if m.IsSpace() {
+			continue
+		}
+
+		r := Region{
+			Offset: m.Offset(int64(l.GlyphStart)),
+			Name:   fmt.Sprintf("Glyph[0x%02X]", id),
+			Kind:   RegionGlyph,
+			Code:   rune(id),
+		}
+
+		c := &Character{BitmapSize: m.BitmapSize}
+		r.Size = glyphSize(c.Width(), c.Height())
+		r.Value = fmt.Sprintf("%dx%d", c.Width(), c.Height())
+		if r.Offset > len(l.Data) {
+			r.Value += fmt.Sprintf(", starts past the end of the file at $%06X", r.Offset)
+			r.Offset, r.Size = len(l.Data), 0
+		} else if r.Offset+r.Size > len(l.Data) {
+			r.Size = len(l.Data)-r.Offset
+			r.Value += fmt.Sprintf(", truncated to %d bytes", r.Size)
+		} else if c, err := m.Character(reader, int64(l.GlyphStart)); err == nil {
+			c.Value = rune(id)
+			r.Char = c
+		}
+
+		glyphs = append(glyphs, r)
+	}
+
+	sort.SliceStable(glyphs, func(i, j int) bool {
+		return glyphs[i].Offset < glyphs[j].Offset
+	})
+
+	for i := range glyphs {
+		for j := i+1; j < len(glyphs) && glyphs[j].Offset < glyphs[i].Offset+glyphs[i].Size; j++ {
+			glyphs[i].Overlaps = append(glyphs[i].Overlaps, glyphs[j].Code)
+			glyphs[j].Overlaps = append(glyphs[j].Overlaps, glyphs[i].Code)
+		}
+	}
+
+	end := l.GlyphStart
+	for _, g := range glyphs {
+		if g.Offset > end {
+			l.Regions = append(l.Regions, Region{
+				Offset: end,
+				Size:   g.Offset - end,
+				Name:   "Gap",
+				Kind:   RegionGap,
+				Value:  describeBytes(l.Data[end:g.Offset]),
+			})
+		}
+		l.Regions = append(l.Regions, g)
+		end = max(end, g.Offset+g.Size)
+	}
+
+	if end < len(l.Data) {
+		l.Regions = append(l.Regions, Region{
+			Offset: end,
+			Size:   len(l.Data) - end,
+			Name:   "Trailing",
+			Kind:   RegionTrailing,
+			Value:  describeBytes(l.Data[end:]),
+		})
+	}
+}
+
+// DumpOptions controls how Layout.WriteDump() prints regions.
+type DumpOptions struct {
+	// Hex lines to print for each region.  Zero prints every line.
+	MaxLines int
+
+	// Draw glyph bitmaps under their bytes.
+	Bitmaps bool
+
+	// Highlight unknown, unused, gap, and overlapping bytes with ANSI colors.
+	// Without color they are marked with a character before the offset.
+	Color bool
+}
+
+const dumpBytesPerLine = 16
+
+// WriteDump prints each region with its offset, size, raw bytes, and decoded
+// value.
+func (l *Layout) WriteDump(w io.Writer, opts DumpOptions) error {
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "Format: %s, %d bytes, glyphs start at $%06X\n", l.Format, len(l.Data), l.GlyphStart)
+	fmt.Fprintln(sb, "  Markers: ? unknown, _ unused, ~ gap or trailing, ! overlapping glyph")
+	fmt.Fprintln(sb)
+
+	for _, r := range l.Regions {
+		marker, highlight := " ", ""
+		switch {
+		case len(r.Overlaps) > 0:
+			marker, highlight = "!", "\x1b[1;31m"
+		case r.Kind == RegionUnknown:
+			marker, highlight = "?", "\x1b[33m"
+		case r.Kind == RegionUnused && r.Value != "all zero":
+			marker, highlight = "_", "\x1b[33m"
+		case r.Kind == RegionUnused:
+			marker, highlight = "_", "\x1b[2m"
+		case r.Kind == RegionGap, r.Kind == RegionTrailing:
+			marker, highlight = "~", "\x1b[31m"
+		}
+		if !opts.Color {
+			highlight = ""
+		}
+
+		value := r.Value
+		if len(r.Overlaps) > 0 {
+			codes := []string{}
+			for _, code := range r.Overlaps {
+				codes = append(codes, fmt.Sprintf("0x%02X", code))
+			}
+			value += ", overlaps " + strings.Join(codes, " ")
+		}
+
+		fmt.Fprintf(sb, "%s%s$%06X %5d  %-24s %s", highlight, marker, r.Offset, r.Size, r.Name, value)
+		if highlight != "" {
+			sb.WriteString("\x1b[0m")
+		}
+		sb.WriteString("\n")
+
+		raw := l.Data[r.Offset:r.Offset+r.Size]
+		for i := 0; i < len(raw); i += dumpBytesPerLine {
+			if opts.MaxLines > 0 && i/dumpBytesPerLine >= opts.MaxLines {
+				fmt.Fprintf(sb, "           ... %d more bytes\n", len(raw)-i)
+				break
+			}
+
+			line := raw[i:min(i+dumpBytesPerLine, len(raw))]
+			fmt.Fprintf(sb, "  $%06X  % X\n", r.Offset+i, line)
+		}
+
+		if opts.Bitmaps && r.Char != nil {
+			for y := 0; y < r.Char.Height(); y++ {
+				sb.WriteString("           ")
+				for x := 0; x < r.Char.Width(); x++ {
+					if r.Char.Pixel(x, y) {
+						sb.WriteString("#")
+					} else {
+						sb.WriteString(".")
+					}
+				}
+				sb.WriteString("\n")
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
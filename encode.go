@@ -0,0 +1,280 @@
+package xeroxfont
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+const (
+	// Value of CharacterMeta5Word.Unknown in every known 5Word font.
+	Default5WordUnknown uint16 = 0xC000
+
+	// The glyph area is padded to a multiple of this many bytes.
+	glyphAreaAlign int = 512
+
+	// Each glyph starts on a multiple of this many bytes.
+	glyphAlign int = 4
+)
+
+// Encode writes the font in the .FNT format given by f.Format.  A font that
+// was loaded from a file and hasn't had glyphs added, removed, or resized is
+// written with its original glyph layout, so an unmodified font is written
+// back byte for byte.  Otherwise the glyph offsets and the header fields that
+// describe the glyph area are rebuilt from the characters.  The width table
+// always comes from CellWidth; see Font.Widths.
+func (f *Font) Encode(w io.Writer) error {
+	if f.Header == nil {
+		return fmt.Errorf("font has no header")
+	}
+
+	format := f.Format
+	if format == FormatUnknown {
+		format = Format9700
+	}
+
+	header := *f.Header
+	metaCount := header.MetaCount()
+
+	// LastCharacter sets the size of the metadata table, and characters past
+	// the end of it can't be written.
+	for r := range f.Characters {
+		if r < 0 || int(r) >= metaCount {
+			return fmt.Errorf("character 0x%02X is past LastCharacter 0x%02X", r, header.LastCharacter)
+		}
+	}
+
+	// Build the glyph area first so the offsets are known.
+	glyphs, offsets := f.sourceGlyphs(format, metaCount)
+	if glyphs == nil {
+		var err error
+		glyphs, offsets, err = f.packGlyphs()
+		if err != nil {
+			return err
+		}
+
+		switch format {
+		case Format9700:
+			// 9700 fonts store the size in bytes instead of words.
+			if len(glyphs) > 0xFFFF {
+				return fmt.Errorf("glyph data too large for a 9700 font: %d bytes", len(glyphs))
+			}
+			header.BitmapSize = uint16(len(glyphs))
+			header.Unknown5Word = 0
+		case Format5Word:
+			header.BitmapSize = 0
+			header.Unknown5Word = uint16(len(glyphs) / 2)
+		}
+	}
+
+	if format != Format9700 && format != Format5Word {
+		return fmt.Errorf("Unsupported format: %s", format)
+	}
+
+	if f.ExtraHeader != nil {
+		err := binary.Write(w, binary.LittleEndian, f.ExtraHeader)
+		if err != nil {
+			return fmt.Errorf("Error writing extra header: %w", err)
+		}
+	}
+
+	err := binary.Write(w, binary.LittleEndian, header)
+	if err != nil {
+		return fmt.Errorf("Error writing main header: %w", err)
+	}
+
+	widths := [256]uint8{}
+	for r, c := range f.Characters {
+		if r < 0 || r > 255 {
+			continue
+		}
+
+		// Keep the file's width if the character's width hasn't changed,
+		// even if the two disagree.
+		if src := f.sourceMeta(r); src != nil && src.CellWidth == c.CellWidth {
+			widths[r] = f.Widths[r]
+		} else if c.CellWidth > 0xFF {
+			widths[r] = 0xFF
+		} else {
+			widths[r] = uint8(c.CellWidth)
+		}
+	}
+
+	err = binary.Write(w, binary.LittleEndian, widths)
+	if err != nil {
+		return fmt.Errorf("Error writing width table: %w", err)
+	}
+
+	for i := 0; i < metaCount; i++ {
+		m := f.characterMeta(rune(i), offsets[rune(i)])
+		if format == Format9700 {
+			err = binary.Write(w, binary.LittleEndian, m.meta9700())
+		} else {
+			err = binary.Write(w, binary.LittleEndian, m.meta5Word())
+		}
+
+		if err != nil {
+			return fmt.Errorf("Error writing metadata for 0x%02X: %w", i, err)
+		}
+	}
+
+	_, err = w.Write(glyphs)
+	if err != nil {
+		return fmt.Errorf("Error writing glyph data: %w", err)
+	}
+
+	return nil
+}
+
+// packGlyphs lays out the glyphs in character order and returns the glyph
+// area and each glyph's offset in words.
+func (f *Font) packGlyphs() ([]byte, map[rune]int, error) {
+	glyphs := &bytes.Buffer{}
+	offsets := make(map[rune]int)
+	for _, r := range f.sortedRunes() {
+		c := f.Characters[r]
+		if c.IsSpace {
+			continue
+		}
+
+		if pad := glyphs.Len() % glyphAlign; pad != 0 {
+			glyphs.Write(make([]byte, glyphAlign - pad))
+		}
+
+		offsets[r] = glyphs.Len() / 2
+		glyphs.Write(c.rawGlyph())
+	}
+
+	if glyphs.Len() / 2 > 0xFFFF {
+		return nil, nil, fmt.Errorf("glyph data too large: %d bytes", glyphs.Len())
+	}
+
+	if pad := glyphs.Len() % glyphAreaAlign; pad != 0 {
+		glyphs.Write(make([]byte, glyphAreaAlign - pad))
+	}
+
+	return glyphs.Bytes(), offsets, nil
+}
+
+// sourceGlyphs writes the glyphs over a copy of the glyph area the font was
+// loaded from, at their original offsets.  It returns nil if the font wasn't
+// loaded from a file, its format or size changed, or any glyph was added,
+// removed, moved, or resized, or runs past the end of the area.  Glyphs that
+// share bytes in the file must still agree on them.
+func (f *Font) sourceGlyphs(format Format, metaCount int) ([]byte, map[rune]int) {
+	src := f.source
+	if src == nil || src.format != format || len(src.meta) != metaCount {
+		return nil, nil
+	}
+
+	area := append([]byte(nil), src.glyphArea...)
+	written := make([]bool, len(area))
+	offsets := make(map[rune]int)
+	for i, m := range src.meta {
+		c, ok := f.Characters[rune(i)]
+		if m.IsSpace() {
+			if ok && !c.IsSpace {
+				return nil, nil
+			}
+			continue
+		}
+
+		if !ok || c.IsSpace || c.GlyphOffset != m.GlyphOffset || c.BitmapSize != m.BitmapSize {
+			return nil, nil
+		}
+
+		// The last glyph may end at the end of the file without its padding
+		// byte.
+		start := m.GlyphOffset * 2
+		raw := c.rawGlyph()
+		if start+len(raw) > len(area)+1 {
+			return nil, nil
+		}
+
+		for j, b := range raw {
+			if start+j >= len(area) {
+				break
+			}
+
+			if written[start+j] && area[start+j] != b {
+				return nil, nil
+			}
+			area[start+j] = b
+			written[start+j] = true
+		}
+		offsets[rune(i)] = m.GlyphOffset
+	}
+
+	return area, offsets
+}
+
+// sourceMeta returns the metadata entry a character was loaded with, or nil.
+func (f *Font) sourceMeta(r rune) *CharacterMeta {
+	if f.source == nil || r < 0 || int(r) >= len(f.source.meta) {
+		return nil
+	}
+	return &f.source.meta[r]
+}
+
+func (f *Font) WriteFile(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("unable to create %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	err = f.Encode(file)
+	if err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+// characterMeta builds the metadata table entry for a character.  Characters
+// not in the font are written as empty spacing characters.
+func (f *Font) characterMeta(r rune, offset int) CharacterMeta {
+	c, ok := f.Characters[r]
+	if !ok {
+		return CharacterMeta{
+			BitmapSize: packBitmapSize(1, 8),
+			Unknown: Default5WordUnknown,
+			Spacing: true,
+		}
+	}
+
+	m := CharacterMeta{
+		BlanksLeft: c.BlanksLeft,
+		BitmapSize: c.BitmapSize,
+		CellWidth: c.CellWidth,
+		Unknown: c.Unknown,
+		Spacing: c.IsSpace,
+	}
+
+	if m.Unknown == 0 {
+		m.Unknown = Default5WordUnknown
+		if src := f.sourceMeta(r); src != nil && f.source.format == Format5Word {
+			m.Unknown = src.Unknown
+		}
+	}
+
+	if !c.IsSpace {
+		m.GlyphOffset = offset
+	} else {
+		m.GlyphOffset = c.GlyphOffset
+	}
+
+	return m
+}
+
+func (f *Font) sortedRunes() []rune {
+	runes := []rune{}
+	for r := range f.Characters {
+		runes = append(runes, r)
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+	return runes
+}
@@ -0,0 +1,49 @@
+package xeroxfont
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// sampleFonts returns every font in sample-fonts.
+func sampleFonts(t *testing.T) []string {
+	files, err := filepath.Glob(filepath.Join("sample-fonts", "*", "*.FNT"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) == 0 {
+		t.Fatal("no sample fonts found")
+	}
+	return files
+}
+
+// TestEncodeSamples loads each sample font and checks that encoding it gives
+// back the same bytes.
+func TestEncodeSamples(t *testing.T) {
+	for _, name := range sampleFonts(t) {
+		t.Run(name, func(t *testing.T) {
+			raw, err := os.ReadFile(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			font, err := LoadFont(bytes.NewReader(raw))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			buf := &bytes.Buffer{}
+			err = font.Encode(buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(buf.Bytes(), raw) {
+				t.Errorf("encoded %d bytes, want %d identical bytes", buf.Len(), len(raw))
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package xeroxfont
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// The glyph names in PostscriptNames follow Windows code page 1252, which is
+// Latin-1 except for 0x80 through 0x9F.  Unused codes map to themselves.
+var cp1252High = [32]rune{
+	0x20AC, 0x0081, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0x008D, 0x017D, 0x008F,
+	0x0090, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0x009D, 0x017E, 0x0178,
+}
+
+// CodeToUnicode returns the Unicode character for a character code.
+func CodeToUnicode(code rune) rune {
+	if code >= 0x80 && code <= 0x9F {
+		return cp1252High[code-0x80]
+	}
+	return code
+}
+
+// UnicodeToCode returns the character code for a Unicode character.  False is
+// returned if there isn't one.
+func UnicodeToCode(r rune) (rune, bool) {
+	if r < 0x80 || (r >= 0xA0 && r <= 0xFF) {
+		return r, true
+	}
+
+	for i, u := range cp1252High {
+		if u == r {
+			return rune(0x80 + i), true
+		}
+	}
+	return 0, false
+}
+
+// Encoding maps a font's character codes to Unicode, for fonts that don't use
+// code page 1252.  Codes can be larger than a byte.
+type Encoding struct {
+	Name string
+
+	toUnicode   map[rune]rune
+	fromUnicode map[rune]rune
+}
+
+// NewEncoding builds an encoding from a map of character codes to Unicode.
+// When several codes map to the same character, the lowest code is used for
+// it.
+func NewEncoding(name string, codes map[rune]rune) *Encoding {
+	e := &Encoding{
+		Name:        name,
+		toUnicode:   map[rune]rune{},
+		fromUnicode: map[rune]rune{},
+	}
+
+	for code, u := range codes {
+		e.toUnicode[code] = u
+		if prev, ok := e.fromUnicode[u]; !ok || code < prev {
+			e.fromUnicode[u] = code
+		}
+	}
+	return e
+}
+
+// ReadEncoding reads a mapping table like the ones published by the Unicode
+// Consortium: lines with a character code and a Unicode value in hex, and
+// comments starting with #.  Lines without a Unicode value are skipped.
+func ReadEncoding(r io.Reader, name string) (*Encoding, error) {
+	codes := map[rune]rune{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(text)
+		if len(fields) < 2 {
+			continue
+		}
+
+		code, err := strconv.ParseUint(fields[0], 0, 32)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: bad character code %q", line, fields[0])
+		}
+
+		value, base := fields[1], 0
+		if strings.HasPrefix(value, "U+") {
+			value, base = value[2:], 16
+		}
+
+		u, err := strconv.ParseUint(value, base, 32)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: bad Unicode value %q", line, fields[1])
+		}
+
+		codes[rune(code)] = rune(u)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(codes) == 0 {
+		return nil, fmt.Errorf("no mappings found")
+	}
+	return NewEncoding(name, codes), nil
+}
+
+// LoadEncodingFile reads a mapping table with ReadEncoding.  The encoding is
+// named after the file.
+func LoadEncodingFile(filename string) (*Encoding, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	name := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	e, err := ReadEncoding(file, name)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read encoding %s: %w", filename, err)
+	}
+	return e, nil
+}
+
+func (e *Encoding) ToUnicode(code rune) (rune, bool) {
+	u, ok := e.toUnicode[code]
+	return u, ok
+}
+
+func (e *Encoding) FromUnicode(r rune) (rune, bool) {
+	code, ok := e.fromUnicode[r]
+	return code, ok
+}
+
+// UnicodeFor returns the Unicode character for one of the font's character
+// codes, using the font's Encoding or code page 1252 if it doesn't have one.
+func (f *Font) UnicodeFor(code rune) (rune, bool) {
+	if f.Encoding != nil {
+		return f.Encoding.ToUnicode(code)
+	}
+
+	if code < 0 || code > 0xFF {
+		return 0, false
+	}
+	return CodeToUnicode(code), true
+}
+
+// CodeFor returns the character code in this font for a Unicode character.
+// Without an Encoding, characters that are already a code in the font are
+// used as-is.
+func (f *Font) CodeFor(r rune) (rune, bool) {
+	var code rune
+	var ok bool
+	if f.Encoding != nil {
+		code, ok = f.Encoding.FromUnicode(r)
+	} else if _, ok = f.Characters[r]; ok {
+		return r, true
+	} else {
+		code, ok = UnicodeToCode(r)
+	}
+
+	if !ok {
+		return 0, false
+	}
+
+	_, ok = f.Characters[code]
+	return code, ok
+}
+
+// noCode stands in for characters the font doesn't have.  It is never a
+// character code.
+const noCode rune = -1
+
+// codes converts Unicode text to the font's character codes.  Characters the
+// font doesn't have become noCode, so they can still take up a cell on the
+// grid.
+func (f *Font) codes(text string) []rune {
+	codes := []rune{}
+	for _, r := range text {
+		code, ok := f.CodeFor(r)
+		if !ok {
+			code = noCode
+		}
+		codes = append(codes, code)
+	}
+	return codes
+}
@@ -14,39 +14,61 @@ import (
 )
 
 type Font struct {
+	ExtraHeader *ExtraHeader // nil if the file doesn't have one
 	Header *FontHeader
+	Format Format
 	Characters map[rune]*Character
+
+	// The width table as it was loaded.  It only has room for the first 256
+	// codes; characters above that only have their CellWidth.  Encode()
+	// derives the table from each character's CellWidth, and only keeps an
+	// entry from here if the character's CellWidth hasn't changed since it
+	// was loaded.  Set CellWidth to change a width.
 	Widths [256]uint8
+
+	// Maps character codes to Unicode.  nil for code page 1252, which only
+	// covers codes up to 255.
+	Encoding *Encoding
+
+	// Lay text out on a grid of FixedWidth cells instead of advancing by
+	// each character's CellWidth.  Characters the font doesn't have still
+	// take up a cell.  Only used by fixed pitch fonts; see IsFixed().
+	Grid bool
+
+	source *fontSource // nil unless loaded from a file
+}
+
+// fontSource is what a font was loaded from.  Encode() uses it to write an
+// unmodified font back out byte for byte, including the metadata it doesn't
+// keep, gaps between glyphs, and anything after the last glyph.
+type fontSource struct {
+	format Format
+	meta   []CharacterMeta
+
+	// Everything after the metadata table.
+	glyphArea []byte
 }
 
 func LoadFont(reader io.ReadSeeker) (*Font, error) {
-	var val byte
-	err := binary.Read(reader, binary.LittleEndian, &val)
-	if err != nil {
-		return nil, fmt.Errorf("Error reading first byte: %w", err)
-	}
+	return LoadFontWithOptions(reader, LoadOptions{})
+}
 
-	reader.Seek(0, 0)
+func LoadFontWithOptions(reader io.ReadSeeker, opts LoadOptions) (*Font, error) {
 	readOffset := 0
 	font := &Font{
-		Header: &FontHeader{},
 		Characters: make(map[rune]*Character),
 		Widths: [256]uint8{},
 	}
 
-	// Just ignore the extra header for now.
-	if !IsOrientation(val) {
-		log.Println("skipping extra header")
-		_, err = reader.Seek(128, io.SeekStart)
-		if err != nil {
-			return nil, fmt.Errorf("Error skipping extra header: %w", err)
-		}
-		readOffset += 128
+	var err error
+	font.ExtraHeader, font.Header, err = readHeaders(reader)
+	if err != nil {
+		return nil, err
 	}
 
-	err = binary.Read(reader, binary.LittleEndian, font.Header)
-	if err != nil {
-		return nil, fmt.Errorf("Error reading main header: %w", err)
+	if font.ExtraHeader != nil {
+		log.Println("found extra header")
+		readOffset += binary.Size(font.ExtraHeader)
 	}
 	readOffset += binary.Size(font.Header)
 	log.Println(font.Header)
@@ -57,29 +79,56 @@ func LoadFont(reader io.ReadSeeker) (*Font, error) {
 	}
 	readOffset += binary.Size(font.Widths)
 
-	var metaCount int = int(font.Header.LastCharacter)
-	if font.Header.LastCharacter < 128 {
-		metaCount = 128
-	} else if font.Header.LastCharacter % 128 != 0 {
-		mod := int(font.Header.LastCharacter) % 128
-		metaCount = int(font.Header.LastCharacter) + (128 - mod)
-	}
+	metaCount := font.Header.MetaCount()
 	//metaTableOffset := readOffset
 	log.Printf("metaCount: %d\n", metaCount)
 
+	font.Format = opts.Format
+	if font.Format == FormatUnknown {
+		det, err := detectFormat(reader, font.Header, int64(readOffset))
+		if err != nil {
+			return nil, fmt.Errorf("Error detecting format: %w", err)
+		}
+		log.Println("detected format:", det)
+
+		if det.Format == FormatUnknown {
+			return nil, fmt.Errorf("Unable to detect metadata format")
+		}
+		font.Format = det.Format
+
+		_, err = reader.Seek(int64(readOffset), io.SeekStart)
+		if err != nil {
+			return nil, fmt.Errorf("Error seeking to metadata table: %w", err)
+		}
+	}
+
 	var meta []CharacterMeta
-	if font.Header.Is9700() {
+	switch font.Format {
+	case Format9700:
 		meta, err = MetaFrom9700(reader, metaCount)
 		readOffset += binary.Size(CharacterMeta9700{}) * metaCount
-	} else {
+	case Format5Word:
 		meta, err = MetaFrom5Word(reader, metaCount)
 		readOffset += binary.Size(CharacterMeta5Word{}) * metaCount
+	default:
+		return nil, fmt.Errorf("Unsupported format: %s", font.Format)
 	}
 
 	if err != nil {
 		return nil, fmt.Errorf("Error parsing metadata: %w", err)
 	}
 
+	_, err = reader.Seek(int64(readOffset), io.SeekStart)
+	if err != nil {
+		return nil, fmt.Errorf("Error seeking to glyph data: %w", err)
+	}
+
+	glyphArea, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading glyph data: %w", err)
+	}
+	font.source = &fontSource{format: font.Format, meta: meta, glyphArea: glyphArea}
+
 	for id, m := range meta {
 		//log.Printf("[font] %d: %s\n", id, m)
 		char, err := m.Character(reader, int64(readOffset))
@@ -95,21 +144,55 @@ func LoadFont(reader io.ReadSeeker) (*Font, error) {
 }
 
 func LoadFontFromFile(filename string) (*Font, error) {
+	return LoadFontFromFileWithOptions(filename, LoadOptions{})
+}
+
+func LoadFontFromFileWithOptions(filename string, opts LoadOptions) (*Font, error) {
 	file, err := os.Open(filename)
 	if err != nil {
 		return nil, err
 	}
 	defer file.Close()
 
-	return LoadFont(file)
+	return LoadFontWithOptions(file, opts)
+}
+
+// Clone returns a deep copy of the font.
+func (f *Font) Clone() *Font {
+	n := &Font{
+		Header: &FontHeader{},
+		Characters: make(map[rune]*Character),
+		Widths: f.Widths,
+		Format: f.Format,
+		Encoding: f.Encoding,
+		Grid: f.Grid,
+		source: f.source,
+	}
+
+	*n.Header = *f.Header
+	if f.ExtraHeader != nil {
+		extra := *f.ExtraHeader
+		n.ExtraHeader = &extra
+	}
+
+	for r, c := range f.Characters {
+		n.Characters[r] = c.Clone()
+	}
+	return n
 }
 
 /*
 	destImg: destination image
 	destPt:  destination start point.  this is the baseline on the destination image.
 	c:       text fill color
+	text:    Unicode text, drawn through the font's Encoding.  see CodeFor().
 */
 func (f *Font) DrawString(destImg draw.Image, destPt image.Point, cl color.Color, text string) {
+	f.drawCodes(destImg, destPt, cl, f.codes(text))
+}
+
+// drawCodes draws character codes like DrawString() draws text.
+func (f *Font) drawCodes(destImg draw.Image, destPt image.Point, cl color.Color, codes []rune) {
 	uni := image.NewUniform(cl)
 	maxHeight := int(f.Header.DistanceBelow) + int(f.Header.DistanceAbove)
 	offset := destPt.X
@@ -117,9 +200,10 @@ func (f *Font) DrawString(destImg draw.Image, destPt image.Point, cl color.Color
 	//	fmt.Printf("maxHeight != PixelHeight: %d != %d\n", maxHeight, f.Header.PixelHeight)
 	//}
 
-	for _, r := range []rune(text) {
-		c, ok := f.Characters[r]
-		if !ok {
+	for _, code := range codes {
+		c, advance := f.codeAdvance(code)
+		if c == nil {
+			offset += advance
 			continue
 		}
 
@@ -134,13 +218,13 @@ func (f *Font) DrawString(destImg draw.Image, destPt image.Point, cl color.Color
 			draw.Over,
 		)
 
-		offset += c.CellWidth
+		offset += advance
 	}
 }
 
 func (f *Font) Render(cl color.Color, text string) image.Image {
 	lines := strings.Split(text, "\n")
-	maxWidth := 0
+	maxWidth := f.Measure(text).Advance
 	pxHeight := len(lines) * int(f.Header.PixelHeight)
 	//maxHeight := pxHeight + ((len(lines)-1) * int(f.Header.LineSpacing))
 	maxHeight := (len(lines)*int(f.Header.LineSpacing))
@@ -150,13 +234,6 @@ func (f *Font) Render(cl color.Color, text string) image.Image {
 	log.Println("LineSpacing:", f.Header.LineSpacing)
 	log.Println("len(lines):", len(lines))
 
-	for _, l := range lines {
-		w := f.textWidth(l)
-		if w > maxWidth {
-			maxWidth = w
-		}
-	}
-
 	img := image.NewRGBA(image.Rect(0, 0, maxWidth, maxHeight))
 	for i, l := range lines {
 		//baseline := ((i+1)*int(f.Header.DistanceAbove)) + (i*(int(f.Header.DistanceBelow)+int(f.Header.LineSpacing)))
@@ -167,14 +244,3 @@ func (f *Font) Render(cl color.Color, text string) image.Image {
 
 	return img
 }
-
-func (f *Font) textWidth(text string) int {
-	l := 0
-	for _, r := range []rune(text) {
-		if chr, ok := f.Characters[r]; ok {
-			l += chr.CellWidth
-		}
-	}
-	return l
-}
-
@@ -0,0 +1,127 @@
+package xeroxfont
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type Weight byte
+
+const (
+	WeightNormal Weight = 'N'
+	WeightBold   Weight = 'B'
+)
+
+func (w Weight) String() string {
+	switch w {
+	case WeightNormal:
+		return "Normal"
+	case WeightBold:
+		return "Bold"
+	}
+	return "Unknown"
+}
+
+// KnownFamilies maps the family code at the start of a font name to a full
+// family name.
+var KnownFamilies = map[string]string{
+	"BS": "Bert Sans",
+	"HA": "Hack",
+}
+
+// FontID is a font name decoded using the Xerox naming convention.  For
+// example, HA10NP is the HA family at 10 points with a normal weight in
+// portrait orientation.
+type FontID struct {
+	Family      string
+	PointSize   int
+	Weight      Weight
+	Orientation Orientation
+}
+
+var reFontID = regexp.MustCompile(`^([A-Z]+?)(\d+)([NB])([PLIJ])$`)
+
+// ParseFontID decodes a font name.  The weight has to be N or B.  Case,
+// surrounding spaces, and null padding are ignored.
+func ParseFontID(name string) (FontID, error) {
+	clean := strings.ToUpper(strings.Trim(name, "\x00 "))
+	match := reFontID.FindStringSubmatch(clean)
+	if match == nil {
+		return FontID{}, fmt.Errorf("%q does not follow the font naming convention", name)
+	}
+
+	size, err := strconv.Atoi(match[2])
+	if err != nil {
+		return FontID{}, fmt.Errorf("bad point size in %q: %w", name, err)
+	}
+
+	return FontID{
+		Family:      match[1],
+		PointSize:   size,
+		Weight:      Weight(match[3][0]),
+		Orientation: Orientation(match[4][0]),
+	}, nil
+}
+
+func (id FontID) String() string {
+	return fmt.Sprintf("%s%d%c%c", id.Family, id.PointSize, id.Weight, id.Orientation)
+}
+
+// FamilyName returns the full name of the family from KnownFamilies, or the
+// family code if it isn't known.
+func (id FontID) FamilyName() string {
+	if name, ok := KnownFamilies[id.Family]; ok {
+		return name
+	}
+	return id.Family
+}
+
+// Validate checks the ID against a font header.
+func (id FontID) Validate(h *FontHeader) error {
+	if id.Orientation != h.Orientation {
+		return fmt.Errorf("name %s is %s but the header is %s", id, id.Orientation, h.Orientation)
+	}
+	return nil
+}
+
+// FontID decodes the font's name.  The main header's name is used first,
+// followed by the names in the extra header.  An error is returned if none of
+// them can be decoded or if the name doesn't match the header.
+func (f *Font) FontID() (FontID, error) {
+	names := []string{string(f.Header.FontName[:])}
+	if f.ExtraHeader != nil {
+		names = append(names,
+			string(f.ExtraHeader.FontNameA[:]),
+			string(f.ExtraHeader.FontNameB[:]),
+		)
+	}
+
+	var err error
+	for _, name := range names {
+		var id FontID
+		id, err = ParseFontID(name)
+		if err != nil {
+			continue
+		}
+		return id, id.Validate(f.Header)
+	}
+
+	return FontID{}, err
+}
+
+// SetFontID writes the ID to the font's name fields.
+func (f *Font) SetFontID(id FontID) error {
+	name := id.String()
+	if len(name) > len(f.Header.FontName) {
+		return fmt.Errorf("font name %s is longer than %d characters", name, len(f.Header.FontName))
+	}
+
+	setField(f.Header.FontName[:], name)
+	if f.ExtraHeader != nil {
+		copy(f.ExtraHeader.FontNameA[:], make([]byte, len(f.ExtraHeader.FontNameA)))
+		copy(f.ExtraHeader.FontNameA[:], name)
+	}
+	return nil
+}
@@ -0,0 +1,555 @@
+package xeroxfont
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// FontJSONVersion is the version of the JSON written by Font.MarshalJSON().
+// Version 2 added GlyphArea.
+const FontJSONVersion = 2
+
+// jsonFont is a whole font as JSON.  Text fields hold their raw bytes, one
+// character per byte, so padding and odd bytes survive.  Unknown and reserved
+// fields are hex.
+type jsonFont struct {
+	Version     int
+	Format      Format
+	ExtraHeader *jsonExtraHeader `json:",omitempty"`
+	Header      jsonHeader
+	Widths      [256]uint8
+	Characters  []jsonCharacter
+	GlyphArea   *jsonGlyphArea `json:",omitempty"`
+}
+
+// jsonGlyphArea is the glyph area of the file a font was loaded from, less
+// its glyphs: its size in bytes, and the non-zero bytes in the gaps between
+// glyphs and after the last one.  It lets Encode() write the file back byte
+// for byte.
+type jsonGlyphArea struct {
+	Size  int
+	Bytes []jsonBytes `json:",omitempty"`
+}
+
+// jsonBytes is a run of bytes in hex, starting at Offset.
+type jsonBytes struct {
+	Offset int
+	Hex    string
+}
+
+type jsonExtraHeader struct {
+	FontFormat FontFormat
+	FontType   string
+	UnknownC   string
+	FontNameA  string
+	FontNameB  string
+	UnknownA   string
+	UnknownB   string
+	Reserved2E string
+	End        string
+}
+
+type jsonHeader struct {
+	Orientation     string
+	FontType        string
+	PixelHeight     uint16
+	LineSpacing     uint16
+	FixedWidth      uint16
+	DistanceBelow   uint16
+	DistanceAbove   uint16
+	DistanceLeading uint16
+	Reserved0E      uint16
+	LastCharacter   uint16
+	BitmapSize      uint16
+	Reserved14      string
+	Unknown5Word    uint16
+	FontName        string
+	Revision        string
+	Reserved20      string
+	Version         string
+	Library         string
+	Reserved2E      string
+}
+
+// jsonCharacter is a metadata table entry and its glyph.  Rows are the
+// upright glyph from top to bottom, with # for ink and . for blank.
+// BitmapSize can be left out when editing and is worked out from Rows.
+type jsonCharacter struct {
+	Code        rune
+	Spacing     bool `json:",omitempty"`
+	BlanksLeft  int
+	CellWidth   int
+	GlyphOffset int
+	BitmapSize  int16
+	Unknown     uint16   `json:",omitempty"`
+	Rows        []string `json:",omitempty"`
+}
+
+// MarshalJSON writes every part of the font, including glyphs and header
+// bytes that aren't understood, so it can be edited and read back with
+// UnmarshalJSON.
+func (f *Font) MarshalJSON() ([]byte, error) {
+	if f.Header == nil {
+		return nil, fmt.Errorf("font has no header")
+	}
+
+	h := f.Header
+	doc := jsonFont{
+		Version: FontJSONVersion,
+		Format:  f.Format,
+		Header: jsonHeader{
+			Orientation:     orientationText(h.Orientation),
+			FontType:        fontTypeName(h.FontType),
+			PixelHeight:     h.PixelHeight,
+			LineSpacing:     h.LineSpacing,
+			FixedWidth:      h.FixedWidth,
+			DistanceBelow:   h.DistanceBelow,
+			DistanceAbove:   h.DistanceAbove,
+			DistanceLeading: h.DistanceLeading,
+			Reserved0E:      h.Reserved0E,
+			LastCharacter:   h.LastCharacter,
+			BitmapSize:      h.BitmapSize,
+			Reserved14:      hex.EncodeToString(h.Reserved14[:]),
+			Unknown5Word:    h.Unknown5Word,
+			FontName:        latin1(h.FontName[:]),
+			Revision:        latin1(h.Revision[:]),
+			Reserved20:      hex.EncodeToString(h.Reserved20[:]),
+			Version:         latin1(h.Version[:]),
+			Library:         latin1(h.Library[:]),
+			Reserved2E:      hex.EncodeToString(h.Reserved2E[:]),
+		},
+		Widths:     f.Widths,
+		Characters: []jsonCharacter{},
+	}
+
+	if e := f.ExtraHeader; e != nil {
+		doc.ExtraHeader = &jsonExtraHeader{
+			FontFormat: e.FontFormat,
+			FontType:   latin1([]byte{e.FontType}),
+			UnknownC:   hex.EncodeToString(e.UnknownC[:]),
+			FontNameA:  latin1(e.FontNameA[:]),
+			FontNameB:  latin1(e.FontNameB[:]),
+			UnknownA:   hex.EncodeToString(e.UnknownA[:]),
+			UnknownB:   hex.EncodeToString(e.UnknownB[:]),
+			Reserved2E: hex.EncodeToString(e.Reserved2E[:]),
+			End:        latin1([]byte{e.End}),
+		}
+	}
+
+	for _, r := range f.sortedRunes() {
+		c := f.Characters[r]
+		jc := jsonCharacter{
+			Code:        r,
+			Spacing:     c.IsSpace,
+			BlanksLeft:  c.BlanksLeft,
+			CellWidth:   c.CellWidth,
+			GlyphOffset: c.GlyphOffset,
+			BitmapSize:  c.BitmapSize,
+			Unknown:     c.Unknown,
+		}
+
+		if !c.IsSpace {
+			for y := 0; y < c.Height(); y++ {
+				row := make([]byte, c.Width())
+				for x := range row {
+					row[x] = '.'
+					if c.Pixel(x, y) {
+						row[x] = '#'
+					}
+				}
+				jc.Rows = append(jc.Rows, string(row))
+			}
+		}
+
+		doc.Characters = append(doc.Characters, jc)
+	}
+
+	doc.GlyphArea = f.jsonGlyphArea()
+	return json.Marshal(doc)
+}
+
+// jsonGlyphArea returns what the source glyph area holds besides glyphs, or
+// nil if the font wasn't loaded from a file.
+func (f *Font) jsonGlyphArea() *jsonGlyphArea {
+	src := f.source
+	if src == nil || src.format != f.Format {
+		return nil
+	}
+
+	glyph := make([]bool, len(src.glyphArea))
+	for _, m := range src.meta {
+		if m.IsSpace() {
+			continue
+		}
+
+		c := &Character{BitmapSize: m.BitmapSize}
+		start := m.GlyphOffset * 2
+		for i := start; i < start+glyphSize(c.Width(), c.Height()) && i < len(glyph); i++ {
+			glyph[i] = true
+		}
+	}
+
+	area := &jsonGlyphArea{Size: len(src.glyphArea)}
+	for i := 0; i < len(glyph); i++ {
+		if glyph[i] || src.glyphArea[i] == 0 {
+			continue
+		}
+
+		end := i
+		for end < len(glyph) && !glyph[end] && src.glyphArea[end] != 0 {
+			end++
+		}
+		area.Bytes = append(area.Bytes, jsonBytes{Offset: i, Hex: hex.EncodeToString(src.glyphArea[i:end])})
+		i = end
+	}
+	return area
+}
+
+// UnmarshalJSON reads a font written by MarshalJSON.  Metadata without a
+// version, as written by older versions of debug --metadata, is still read;
+// its characters have metrics but blank glyphs.
+func (f *Font) UnmarshalJSON(raw []byte) error {
+	version := struct{ Version int }{}
+	err := json.Unmarshal(raw, &version)
+	if err != nil {
+		return err
+	}
+
+	if version.Version == 0 {
+		type legacyFont Font
+		return json.Unmarshal(raw, (*legacyFont)(f))
+	}
+
+	if version.Version > FontJSONVersion {
+		return fmt.Errorf("font JSON version %d is newer than %d", version.Version, FontJSONVersion)
+	}
+
+	doc := jsonFont{}
+	err = json.Unmarshal(raw, &doc)
+	if err != nil {
+		return err
+	}
+
+	font := Font{
+		Header:     &FontHeader{},
+		Format:     doc.Format,
+		Characters: make(map[rune]*Character),
+		Widths:     doc.Widths,
+	}
+
+	h, jh := font.Header, doc.Header
+	h.Orientation, err = parseOrientationText(jh.Orientation)
+	if err != nil {
+		return err
+	}
+
+	h.FontType, err = parseFontTypeText(jh.FontType)
+	if err != nil {
+		return err
+	}
+
+	h.PixelHeight = jh.PixelHeight
+	h.LineSpacing = jh.LineSpacing
+	h.FixedWidth = jh.FixedWidth
+	h.DistanceBelow = jh.DistanceBelow
+	h.DistanceAbove = jh.DistanceAbove
+	h.DistanceLeading = jh.DistanceLeading
+	h.Reserved0E = jh.Reserved0E
+	h.LastCharacter = jh.LastCharacter
+	h.BitmapSize = jh.BitmapSize
+	h.Unknown5Word = jh.Unknown5Word
+
+	err = firstError(
+		setHex(h.Reserved14[:], jh.Reserved14, "Reserved14"),
+		setLatin1(h.FontName[:], jh.FontName, "FontName"),
+		setLatin1(h.Revision[:], jh.Revision, "Revision"),
+		setHex(h.Reserved20[:], jh.Reserved20, "Reserved20"),
+		setLatin1(h.Version[:], jh.Version, "Version"),
+		setLatin1(h.Library[:], jh.Library, "Library"),
+		setHex(h.Reserved2E[:], jh.Reserved2E, "Reserved2E"),
+	)
+	if err != nil {
+		return fmt.Errorf("Header: %w", err)
+	}
+
+	if je := doc.ExtraHeader; je != nil {
+		e := &ExtraHeader{FontFormat: je.FontFormat}
+		fontType, end := []byte{0}, []byte{0}
+		err = firstError(
+			setLatin1(fontType, je.FontType, "FontType"),
+			setHex(e.UnknownC[:], je.UnknownC, "UnknownC"),
+			setLatin1(e.FontNameA[:], je.FontNameA, "FontNameA"),
+			setLatin1(e.FontNameB[:], je.FontNameB, "FontNameB"),
+			setHex(e.UnknownA[:], je.UnknownA, "UnknownA"),
+			setHex(e.UnknownB[:], je.UnknownB, "UnknownB"),
+			setHex(e.Reserved2E[:], je.Reserved2E, "Reserved2E"),
+			setLatin1(end, je.End, "End"),
+		)
+		if err != nil {
+			return fmt.Errorf("ExtraHeader: %w", err)
+		}
+
+		e.FontType, e.End = fontType[0], end[0]
+		font.ExtraHeader = e
+	}
+
+	for _, jc := range doc.Characters {
+		c, err := jc.character()
+		if err != nil {
+			return fmt.Errorf("character 0x%02X: %w", jc.Code, err)
+		}
+
+		if _, ok := font.Characters[c.Value]; ok {
+			return fmt.Errorf("character 0x%02X is listed twice", jc.Code)
+		}
+		font.Characters[c.Value] = c
+	}
+
+	if doc.GlyphArea != nil {
+		font.source, err = doc.GlyphArea.source(&font)
+		if err != nil {
+			return fmt.Errorf("GlyphArea: %w", err)
+		}
+	}
+
+	*f = font
+	return nil
+}
+
+// source rebuilds the fontSource a font was written from.  The metadata comes
+// from the characters, so Encode() only lays glyphs out as the file did while
+// they still fit there; see sourceGlyphs().
+func (ja *jsonGlyphArea) source(font *Font) (*fontSource, error) {
+	if ja.Size < 0 {
+		return nil, fmt.Errorf("Size is %d", ja.Size)
+	}
+
+	src := &fontSource{
+		format:    font.Format,
+		meta:      make([]CharacterMeta, font.Header.MetaCount()),
+		glyphArea: make([]byte, ja.Size),
+	}
+
+	for i := range src.meta {
+		c, ok := font.Characters[rune(i)]
+		if !ok {
+			src.meta[i] = CharacterMeta{Spacing: true}
+			continue
+		}
+
+		src.meta[i] = CharacterMeta{
+			BlanksLeft:  c.BlanksLeft,
+			GlyphOffset: c.GlyphOffset,
+			Unknown:     c.Unknown,
+			BitmapSize:  c.BitmapSize,
+			CellWidth:   c.CellWidth,
+			Spacing:     c.IsSpace,
+		}
+	}
+
+	for _, jb := range ja.Bytes {
+		raw, err := hex.DecodeString(jb.Hex)
+		if err != nil {
+			return nil, fmt.Errorf("bytes at %d: %w", jb.Offset, err)
+		}
+
+		if jb.Offset < 0 || jb.Offset+len(raw) > ja.Size {
+			return nil, fmt.Errorf("bytes at %d run past Size %d", jb.Offset, ja.Size)
+		}
+		copy(src.glyphArea[jb.Offset:], raw)
+	}
+	return src, nil
+}
+
+func (jc jsonCharacter) character() (*Character, error) {
+	c := &Character{
+		Value:       jc.Code,
+		GlyphOffset: jc.GlyphOffset,
+		Unknown:     jc.Unknown,
+	}
+
+	if jc.Spacing {
+		c.SetIsSpace(true)
+		if jc.BitmapSize != 0 {
+			c.BitmapSize = jc.BitmapSize
+		}
+	} else {
+		if len(jc.Rows) == 0 {
+			return nil, fmt.Errorf("glyph has no rows")
+		}
+
+		width, height := len(jc.Rows[0]), len(jc.Rows)
+		if jc.BitmapSize != 0 {
+			size := &Character{BitmapSize: jc.BitmapSize}
+			if size.Width() != width || size.Height() != height {
+				return nil, fmt.Errorf("rows are %dx%d but BitmapSize is %dx%d", width, height, size.Width(), size.Height())
+			}
+		}
+
+		err := c.Resize(width, height)
+		if err != nil {
+			return nil, err
+		}
+
+		// Short glyphs are padded on top, like SetFromImage.
+		shift := c.Height() - height
+		for y, row := range jc.Rows {
+			if len(row) != width {
+				return nil, fmt.Errorf("row %d is %d pixels wide instead of %d", y, len(row), width)
+			}
+
+			for x, p := range row {
+				switch p {
+				case '#':
+					c.SetPixel(x, y+shift, true)
+				case '.':
+				default:
+					return nil, fmt.Errorf("row %d has %q; use # or .", y, p)
+				}
+			}
+		}
+
+		if jc.BitmapSize != 0 {
+			c.BitmapSize = jc.BitmapSize
+		}
+	}
+
+	err := c.SetBlanksLeft(jc.BlanksLeft)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.SetCellWidth(jc.CellWidth)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// LoadFontJSONFile reads a font written by WriteJSONFile.
+func LoadFontJSONFile(filename string) (*Font, error) {
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	font := &Font{}
+	err = json.Unmarshal(raw, font)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse font %s: %w", filename, err)
+	}
+
+	if font.Header == nil {
+		return nil, fmt.Errorf("font %s has no header", filename)
+	}
+	return font, nil
+}
+
+// WriteJSONFile writes the font as indented JSON.
+func (f *Font) WriteJSONFile(filename string) error {
+	raw, err := json.MarshalIndent(f, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, raw, 0664)
+}
+
+// orientationText is the orientation's name, or its raw byte if it isn't a
+// known orientation.
+func orientationText(o Orientation) string {
+	if IsOrientation(byte(o)) {
+		return o.String()
+	}
+	return latin1([]byte{byte(o)})
+}
+
+func parseOrientationText(text string) (Orientation, error) {
+	o, err := ParseOrientation(text)
+	if err == nil {
+		return o, nil
+	}
+
+	raw := []byte{0}
+	if setLatin1(raw, text, "Orientation") != nil || len([]rune(text)) != 1 {
+		return 0, err
+	}
+	return Orientation(raw[0]), nil
+}
+
+func parseFontTypeText(text string) (byte, error) {
+	switch text {
+	case "Proportional":
+		return 'P', nil
+	case "Fixed":
+		return 'F', nil
+	}
+
+	raw := []byte{0}
+	if setLatin1(raw, text, "FontType") != nil || len([]rune(text)) != 1 {
+		return 0, fmt.Errorf("Unknown font type: %q", text)
+	}
+	return raw[0], nil
+}
+
+// latin1 maps each byte to the character with the same value, so any bytes
+// can be stored in a JSON string.
+func latin1(raw []byte) string {
+	sb := &strings.Builder{}
+	for _, b := range raw {
+		sb.WriteRune(rune(b))
+	}
+	return sb.String()
+}
+
+// setLatin1 fills a fixed width field from a string written by latin1().
+// Short strings are padded with spaces, like setField().
+func setLatin1(field []byte, text, name string) error {
+	runes := []rune(text)
+	if len(runes) > len(field) {
+		return fmt.Errorf("%s is longer than %d characters", name, len(field))
+	}
+
+	for i := range field {
+		field[i] = ' '
+		if i >= len(runes) {
+			continue
+		}
+
+		if runes[i] > 0xFF {
+			return fmt.Errorf("%s has a character that isn't a single byte: %q", name, runes[i])
+		}
+		field[i] = byte(runes[i])
+	}
+	return nil
+}
+
+// setHex fills a field from hex.  An empty string leaves it zeroed.
+func setHex(field []byte, text, name string) error {
+	if text == "" {
+		return nil
+	}
+
+	raw, err := hex.DecodeString(text)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	if len(raw) != len(field) {
+		return fmt.Errorf("%s is %d bytes instead of %d", name, len(raw), len(field))
+	}
+	copy(field, raw)
+	return nil
+}
+
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package xeroxfont
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+// jsonRoundTrip writes a font file as JSON, reads it back and encodes it.
+func jsonRoundTrip(t *testing.T, raw []byte) []byte {
+	font, err := LoadFont(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := json.Marshal(font)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	back := &Font{}
+	err = json.Unmarshal(doc, back)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	err = back.Encode(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// TestJSONSamples checks that each sample font survives JSON unchanged.
+func TestJSONSamples(t *testing.T) {
+	for _, name := range sampleFonts(t) {
+		t.Run(name, func(t *testing.T) {
+			raw, err := os.ReadFile(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := jsonRoundTrip(t, raw)
+			if !bytes.Equal(got, raw) {
+				t.Errorf("encoded %d bytes, want %d identical bytes", len(got), len(raw))
+			}
+		})
+	}
+}
+
+// TestJSONGlyphAreaBytes checks that bytes between and after glyphs survive
+// JSON.
+func TestJSONGlyphAreaBytes(t *testing.T) {
+	name := sampleFonts(t)[0]
+	raw, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	font, err := LoadFont(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	glyph := make([]bool, len(font.source.glyphArea))
+	for _, m := range font.source.meta {
+		if m.IsSpace() {
+			continue
+		}
+
+		c := &Character{BitmapSize: m.BitmapSize}
+		for i := 0; i < glyphSize(c.Width(), c.Height()); i++ {
+			glyph[m.GlyphOffset*2+i] = true
+		}
+	}
+
+	// Mark every byte that isn't part of a glyph, and add some at the end.
+	start := len(raw) - len(glyph)
+	changed := 0
+	for i, g := range glyph {
+		if !g {
+			raw[start+i] = 0xA5
+			changed++
+		}
+	}
+	raw = append(raw, 0x12, 0x34, 0x56)
+
+	if changed == 0 {
+		t.Fatalf("%s has no gaps between glyphs", name)
+	}
+
+	got := jsonRoundTrip(t, raw)
+	if !bytes.Equal(got, raw) {
+		t.Errorf("encoded %d bytes, want %d identical bytes", len(got), len(raw))
+	}
+}
@@ -0,0 +1,93 @@
+package xeroxfont
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// IsFixed returns true for fixed pitch fonts, which have a FontType of F and
+// a FixedWidth.
+func (f *Font) IsFixed() bool {
+	return f.Header.FontType == 'F' && f.Header.FixedWidth > 0
+}
+
+// gridded returns true if text is laid out on the FixedWidth grid.
+func (f *Font) gridded() bool {
+	return f.Grid && f.IsFixed()
+}
+
+// advance returns the character to draw for a Unicode character and how far
+// to move after it.  See codeAdvance().
+func (f *Font) advance(r rune) (*Character, int) {
+	code, ok := f.CodeFor(r)
+	if !ok {
+		code = noCode
+	}
+	return f.codeAdvance(code)
+}
+
+// codeAdvance returns the character for a character code and how far to move
+// after it.  The character is nil if the font doesn't have it.  On the grid
+// every code is one cell wide, including missing ones.
+func (f *Font) codeAdvance(code rune) (*Character, int) {
+	c := f.Characters[code]
+	if f.gridded() {
+		return c, int(f.Header.FixedWidth)
+	}
+
+	if c == nil {
+		return nil, 0
+	}
+	return c, c.CellWidth
+}
+
+// CellOrigin returns the start of the baseline of a cell on the grid, counting
+// columns and lines from zero at origin.  Lines are LineSpacing apart.
+// Proportional fonts, or fonts without Grid set, have no columns and only
+// move down by line.
+func (f *Font) CellOrigin(origin image.Point, column, line int) image.Point {
+	pt := origin.Add(image.Pt(0, line*int(f.Header.LineSpacing)))
+	if f.gridded() {
+		pt.X += column * int(f.Header.FixedWidth)
+	}
+	return pt
+}
+
+// DrawColumn draws text starting at a column of the grid.  See CellOrigin().
+func (f *Font) DrawColumn(destImg draw.Image, origin image.Point, column, line int, cl color.Color, text string) {
+	f.DrawString(destImg, f.CellOrigin(origin, column, line), cl, text)
+}
+
+// GridMismatch is a glyph whose CellWidth doesn't match the font's
+// FixedWidth.
+type GridMismatch struct {
+	Code      rune
+	CellWidth int
+}
+
+func (m GridMismatch) String() string {
+	return fmt.Sprintf("0x%02X cell width %d", m.Code, m.CellWidth)
+}
+
+// GridMismatches returns the characters of a fixed pitch font whose CellWidth
+// is not FixedWidth, in code order.  On the grid these still take up one
+// cell, so they can spill into the next cell or leave a gap.  Spacing
+// characters with no width are unused entries and aren't included.  Fonts
+// that aren't fixed pitch have no mismatches.
+func (f *Font) GridMismatches() []GridMismatch {
+	if !f.IsFixed() {
+		return nil
+	}
+
+	mismatches := []GridMismatch{}
+	for _, r := range f.sortedRunes() {
+		c := f.Characters[r]
+		if c.CellWidth == int(f.Header.FixedWidth) || c.IsSpace && c.CellWidth == 0 {
+			continue
+		}
+		mismatches = append(mismatches, GridMismatch{Code: r, CellWidth: c.CellWidth})
+	}
+	return mismatches
+}
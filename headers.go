@@ -69,6 +69,17 @@ func (o Orientation) String() string {
 	return "Unknown"
 }
 
+// ParseOrientation returns the Orientation for a name as returned by
+// Orientation.String() or for its single character code (eg, "P").
+func ParseOrientation(name string) (Orientation, error) {
+	for _, o := range []Orientation{Portrait, Landscape, InvertedPortrait, InvertedLandscape} {
+		if strings.EqualFold(name, o.String()) || name == string(rune(o)) {
+			return o, nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown orientation: %q", name)
+}
+
 func IsOrientation(val byte) bool {
 	switch Orientation(val) {
 	case Portrait, Landscape, InvertedPortrait, InvertedLandscape:
@@ -89,7 +100,7 @@ type ExtraHeader struct {
 	UnknownA [4]byte
 	UnknownB [12]byte
 
-	_ [81]byte
+	Reserved2E [81]byte
 	End byte
 }
 
@@ -122,7 +133,8 @@ func (h ExtraHeader) Is9700() bool {
 	//return true
 }
 
-// Standard header
+// Standard header.  Reserved fields aren't understood but are kept so fonts
+// are written back with the same bytes.
 type FontHeader struct {
 	Orientation Orientation
 	FontType byte // fixed or proportional
@@ -132,34 +144,25 @@ type FontHeader struct {
 	DistanceBelow uint16
 	DistanceAbove uint16
 	DistanceLeading uint16
-	_ uint16
+	Reserved0E uint16
 	LastCharacter uint16
 
 	// BitmapSize and Unknown5Word don't seem to ever both have a value.  9700
 	// fonts will fill BitmapSize, while 5Word fonts will fill Unknown5Word.
 	BitmapSize uint16
-	_ [2]byte
+	Reserved14 [2]byte
 	Unknown5Word uint16
 
 	FontName [6]byte
 	Revision [2]byte
-	_ [2]byte
+	Reserved20 [2]byte
 	Version [2]byte
 	Library [10]byte
 
-	_ [210]byte
+	Reserved2E [210]byte
 }
 
 func (h FontHeader) MarshalJSON() ([]byte, error) {
-	t := string(h.FontType)
-
-	switch h.FontType {
-	case 'P':
-		t = "Proportional"
-	case 'F':
-		t = "Fixed"
-	}
-
 	data := struct {
 		Orientation string
 		FontType string
@@ -180,7 +183,7 @@ func (h FontHeader) MarshalJSON() ([]byte, error) {
 		Library string
 	}{
 		Orientation: h.Orientation.String(),
-		FontType: t,
+		FontType: fontTypeName(h.FontType),
 		PixelHeight: int(h.PixelHeight),
 		LineSpacing: int(h.LineSpacing),
 		FixedWidth: int(h.FixedWidth),
@@ -201,6 +204,82 @@ func (h FontHeader) MarshalJSON() ([]byte, error) {
 	return json.MarshalIndent(data, "", "    ")
 }
 
+// MetaCount returns the number of entries in the character metadata table.
+// This is the last character rounded up to the nearest 128.  Fonts with
+// double-byte or extended character sets can have more than 256.
+func (h FontHeader) MetaCount() int {
+	if h.LastCharacter < 128 {
+		return 128
+	} else if h.LastCharacter % 128 != 0 {
+		mod := int(h.LastCharacter) % 128
+		return int(h.LastCharacter) + (128 - mod)
+	}
+	return int(h.LastCharacter)
+}
+
+func (h *FontHeader) UnmarshalJSON(raw []byte) error {
+	data := struct {
+		Orientation string
+		FontType string
+		PixelHeight uint16
+		LineSpacing uint16
+		FixedWidth uint16
+		DistanceBelow uint16
+		DistanceAbove uint16
+		DistanceLeading uint16
+		LastCharacter uint16
+
+		BitmapSize uint16
+		Unknown5Word uint16
+
+		FontName string
+		Revision string
+		Version string
+		Library string
+	}{}
+
+	err := json.Unmarshal(raw, &data)
+	if err != nil {
+		return err
+	}
+
+	h.Orientation, err = ParseOrientation(data.Orientation)
+	if err != nil {
+		return err
+	}
+
+	switch data.FontType {
+	case "Proportional":
+		h.FontType = 'P'
+	case "Fixed":
+		h.FontType = 'F'
+	default:
+		if len(data.FontType) != 1 {
+			return fmt.Errorf("Unknown font type: %q", data.FontType)
+		}
+		h.FontType = data.FontType[0]
+	}
+
+	h.PixelHeight = data.PixelHeight
+	h.LineSpacing = data.LineSpacing
+	h.FixedWidth = data.FixedWidth
+	h.DistanceBelow = data.DistanceBelow
+	h.DistanceAbove = data.DistanceAbove
+	h.DistanceLeading = data.DistanceLeading
+	h.LastCharacter = data.LastCharacter
+	h.BitmapSize = data.BitmapSize
+	h.Unknown5Word = data.Unknown5Word
+
+	setField(h.FontName[:], data.FontName)
+	setField(h.Revision[:], data.Revision)
+	setField(h.Version[:], data.Version)
+	setField(h.Library[:], data.Library)
+
+	return nil
+}
+
+// Is9700 returns true if BitmapSize is set, which is usually only the case in
+// 9700 fonts.  It's only a hint; see DetectFormat().
 func (h FontHeader) Is9700() bool {
 	if h.BitmapSize == 0 {
 		return false
@@ -226,3 +305,31 @@ func (h FontHeader) String() string {
 	return sb.String()
 }
 
+
+func fontTypeName(t byte) string {
+	switch t {
+	case 'P':
+		return "Proportional"
+	case 'F':
+		return "Fixed"
+	}
+	return string(t)
+}
+
+// fieldString returns a fixed width text field from a header without any
+// null or space padding.
+func fieldString(field []byte) string {
+	return strings.Trim(string(field), "\x00 ")
+}
+
+// setField copies a string into a fixed width text field, padding it with
+// spaces.  Strings that are too long are truncated.
+func setField(field []byte, value string) {
+	for i := range field {
+		if i < len(value) {
+			field[i] = value[i]
+		} else {
+			field[i] = ' '
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package xeroxfont
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"sort"
+)
+
+// IdentifyOptions controls how Identify() reads the sample image.  Zero
+// values are replaced with defaults.
+type IdentifyOptions struct {
+	// Pixels darker than this are ink.  Defaults to 128.
+	Threshold uint8
+
+	// How far, in pixels, a glyph may be shifted when lining it up with a
+	// character.  Defaults to 1.
+	MaxShift int
+
+	// How much larger or smaller, in pixels, a character's ink may be than
+	// a glyph to still be compared with it.  Defaults to 2.
+	SizeTolerance int
+}
+
+func (o *IdentifyOptions) setDefaults() {
+	if o.Threshold == 0 {
+		o.Threshold = 128
+	}
+
+	if o.MaxShift <= 0 {
+		o.MaxShift = 1
+	}
+
+	if o.SizeTolerance <= 0 {
+		o.SizeTolerance = 2
+	}
+}
+
+// FontMatch is a candidate font for a text sample.
+type FontMatch struct {
+	Name string
+	Font *Font
+
+	// Average overlap between each glyph and its best character, from 0 to
+	// 1.  Overlap is the ink both have divided by the ink either has.
+	Score float64
+
+	// Glyphs that had a character of about the same size to compare with.
+	Matched int
+	Glyphs  int
+
+	// The sample read with this font's best characters.
+	Text string
+}
+
+// inkMap is a cropped one bit bitmap.
+type inkMap struct {
+	bounds image.Rectangle
+	bits   []bool
+	count  int
+}
+
+func newInkMap(bounds image.Rectangle) *inkMap {
+	return &inkMap{bounds: bounds, bits: make([]bool, bounds.Dx()*bounds.Dy())}
+}
+
+func (m *inkMap) at(x, y int) bool {
+	if !(image.Point{x, y}.In(m.bounds)) {
+		return false
+	}
+	return m.bits[(y-m.bounds.Min.Y)*m.bounds.Dx() + x-m.bounds.Min.X]
+}
+
+func (m *inkMap) set(x, y int) {
+	i := (y-m.bounds.Min.Y)*m.bounds.Dx() + x-m.bounds.Min.X
+	if !m.bits[i] {
+		m.bits[i] = true
+		m.count++
+	}
+}
+
+// template is a character's ink, cropped, with a list of its pixels.
+type template struct {
+	char   *Character
+	w, h   int
+	pixels []image.Point
+}
+
+// Identify splits the text in a rendered or scanned sample into glyphs and
+// compares them with the characters of every font in the library.  The sample
+// needs to be at the fonts' resolution.  Matches are returned best first.
+func Identify(img image.Image, lib *FontLibrary, opts IdentifyOptions) ([]FontMatch, error) {
+	opts.setDefaults()
+
+	glyphs := segmentGlyphs(img, opts.Threshold)
+	if len(glyphs) == 0 {
+		return nil, fmt.Errorf("no glyphs found in the sample")
+	}
+
+	matches := []FontMatch{}
+	for _, name := range lib.Names() {
+		font, _ := lib.Font(name)
+		templates := fontTemplates(font)
+
+		match := FontMatch{Name: name, Font: font, Glyphs: len(glyphs)}
+		total := 0.0
+		text := []rune{}
+		for _, g := range glyphs {
+			var best *Character
+			bestScore := 0.0
+			for _, t := range templates {
+				dw, dh := t.w-g.bounds.Dx(), t.h-g.bounds.Dy()
+				if max(dw, -dw) > opts.SizeTolerance || max(dh, -dh) > opts.SizeTolerance {
+					continue
+				}
+
+				score := t.overlap(g, opts.MaxShift)
+				if best == nil || score > bestScore {
+					best, bestScore = t.char, score
+				}
+			}
+
+			if best == nil {
+				text = append(text, '?')
+				continue
+			}
+
+			match.Matched++
+			total += bestScore
+			if u, ok := font.UnicodeFor(best.Value); ok {
+				text = append(text, u)
+			} else {
+				text = append(text, '?')
+			}
+		}
+
+		match.Score = total / float64(len(glyphs))
+		match.Text = string(text)
+		matches = append(matches, match)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	return matches, nil
+}
+
+func fontTemplates(font *Font) []*template {
+	templates := []*template{}
+	for _, r := range font.sortedRunes() {
+		c := font.Characters[r]
+		if c.IsSpace {
+			continue
+		}
+
+		ink := c.InkBounds()
+		if ink.Empty() {
+			continue
+		}
+
+		t := &template{char: c, w: ink.Dx(), h: ink.Dy()}
+		for y := ink.Min.Y; y < ink.Max.Y; y++ {
+			for x := ink.Min.X; x < ink.Max.X; x++ {
+				if c.Pixel(x, y) {
+					t.pixels = append(t.pixels, image.Pt(x-ink.Min.X, y-ink.Min.Y))
+				}
+			}
+		}
+		templates = append(templates, t)
+	}
+	return templates
+}
+
+// overlap lines the template up with the middle of the glyph, tries shifting
+// it by up to maxShift pixels, and returns the best intersection over union.
+func (t *template) overlap(g *inkMap, maxShift int) float64 {
+	cx := g.bounds.Min.X + (g.bounds.Dx()-t.w)/2
+	cy := g.bounds.Min.Y + (g.bounds.Dy()-t.h)/2
+
+	best := 0.0
+	for dy := -maxShift; dy <= maxShift; dy++ {
+		for dx := -maxShift; dx <= maxShift; dx++ {
+			both := 0
+			for _, p := range t.pixels {
+				if g.at(cx+dx+p.X, cy+dy+p.Y) {
+					both++
+				}
+			}
+
+			score := float64(both) / float64(len(t.pixels)+g.count-both)
+			best = max(best, score)
+		}
+	}
+	return best
+}
+
+// segmentGlyphs splits the image into lines at blank rows, finds groups of
+// connected ink, and merges groups in a line that share columns, like the dot
+// of an i or an accent.  Glyphs are returned in reading order.
+func segmentGlyphs(img image.Image, threshold uint8) []*inkMap {
+	bounds := img.Bounds()
+	ink := newInkMap(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			cl := img.At(x, y)
+			_, _, _, a := cl.RGBA()
+			if a > 0x8000 && color.GrayModel.Convert(cl).(color.Gray).Y < threshold {
+				ink.set(x, y)
+			}
+		}
+	}
+
+	// Line number of each row, or -1 for blank rows.
+	lineOf := make([]int, bounds.Dy())
+	lines := 0
+	for y := 0; y < bounds.Dy(); y++ {
+		lineOf[y] = -1
+		for x := 0; x < bounds.Dx(); x++ {
+			if ink.bits[y*bounds.Dx() + x] {
+				if y == 0 || lineOf[y-1] == -1 {
+					lines++
+				}
+				lineOf[y] = lines-1
+				break
+			}
+		}
+	}
+
+	// Connected components, eight way
+	type part struct {
+		points []image.Point
+		rect   image.Rectangle
+	}
+
+	seen := make([]bool, len(ink.bits))
+	byLine := make([][]*part, lines)
+	for i, on := range ink.bits {
+		if !on || seen[i] {
+			continue
+		}
+
+		pt := &part{}
+		stack := []int{i}
+		seen[i] = true
+		for len(stack) > 0 {
+			j := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			p := image.Pt(bounds.Min.X + j%bounds.Dx(), bounds.Min.Y + j/bounds.Dx())
+			pt.points = append(pt.points, p)
+			pt.rect = pt.rect.Union(image.Rect(p.X, p.Y, p.X+1, p.Y+1))
+
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					n := image.Pt(p.X+dx, p.Y+dy)
+					if !n.In(bounds) {
+						continue
+					}
+
+					k := (n.Y-bounds.Min.Y)*bounds.Dx() + n.X-bounds.Min.X
+					if ink.bits[k] && !seen[k] {
+						seen[k] = true
+						stack = append(stack, k)
+					}
+				}
+			}
+		}
+
+		line := lineOf[pt.rect.Min.Y-bounds.Min.Y]
+		byLine[line] = append(byLine[line], pt)
+	}
+
+	glyphs := []*inkMap{}
+	for _, parts := range byLine {
+		sort.SliceStable(parts, func(i, j int) bool {
+			return parts[i].rect.Min.X < parts[j].rect.Min.X
+		})
+
+		// Merge parts where at least half of the narrower one's columns are
+		// shared.
+		for i := 0; i < len(parts); i++ {
+			for j := i+1; j < len(parts); {
+				a, b := parts[i].rect, parts[j].rect
+				shared := min(a.Max.X, b.Max.X) - max(a.Min.X, b.Min.X)
+				if shared*2 < min(a.Dx(), b.Dx()) {
+					j++
+					continue
+				}
+
+				parts[i].rect = a.Union(b)
+				parts[i].points = append(parts[i].points, parts[j].points...)
+				parts = append(parts[:j], parts[j+1:]...)
+				j = i+1
+			}
+		}
+
+		for _, pt := range parts {
+			g := newInkMap(pt.rect)
+			for _, p := range pt.points {
+				g.set(p.X, p.Y)
+			}
+			glyphs = append(glyphs, g)
+		}
+	}
+	return glyphs
+}
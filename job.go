@@ -0,0 +1,218 @@
+package xeroxfont
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+)
+
+func (a Align) String() string {
+	switch a {
+	case AlignLeft:
+		return "left"
+	case AlignCenter:
+		return "center"
+	case AlignRight:
+		return "right"
+	}
+	return fmt.Sprintf("Align(%d)", int(a))
+}
+
+func (a Align) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+func (a *Align) UnmarshalText(text []byte) error {
+	for _, v := range []Align{AlignLeft, AlignCenter, AlignRight} {
+		if strings.EqualFold(string(text), v.String()) {
+			*a = v
+			return nil
+		}
+	}
+	return fmt.Errorf("Unknown alignment: %q", string(text))
+}
+
+// Job is a page of positioned text drawn over an optional background form.
+// Paths are relative to the job file.
+type Job struct {
+	// Directories of fonts to load into the library.
+	Library []string `json:",omitempty"`
+
+	// Short names for library fonts used by the items.
+	Fonts map[string]string `json:",omitempty"`
+
+	// Image drawn under the text.  The page is the size of the image unless
+	// Width and Height are set.
+	Background string `json:",omitempty"`
+
+	Width  int `json:",omitempty"`
+	Height int `json:",omitempty"`
+
+	Items []JobItem
+
+	dir string
+}
+
+// JobItem is a piece of text on the page.  X and Y are the start of the
+// baseline, or its middle or end depending on the alignment.  Lines after the
+// first are LineSpacing further down.
+type JobItem struct {
+	X, Y  int
+	Font  string
+	Text  string
+	Color string `json:",omitempty"` // #RGB, #RRGGBB, or #RRGGBBAA.  Defaults to black.
+	Align Align  `json:",omitempty"`
+
+	// Lay the text out on the font's grid of FixedWidth cells, and start it
+	// Column cells right of X.  Only fixed pitch fonts have a grid.
+	Grid   bool `json:",omitempty"`
+	Column int  `json:",omitempty"`
+}
+
+func LoadJob(filename string) (*Job, error) {
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &Job{}
+	err = json.Unmarshal(raw, job)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse job %s: %w", filename, err)
+	}
+
+	job.dir = filepath.Dir(filename)
+	return job, nil
+}
+
+func (j *Job) path(name string) string {
+	if filepath.IsAbs(name) || j.dir == "" {
+		return name
+	}
+	return filepath.Join(j.dir, name)
+}
+
+// LoadLibrary loads the job's font directories.
+func (j *Job) LoadLibrary() (*FontLibrary, error) {
+	dirs := []string{}
+	for _, dir := range j.Library {
+		dirs = append(dirs, j.path(dir))
+	}
+	return LoadFontLibrary(dirs...)
+}
+
+// Render draws the job's items with fonts from the library.
+func (j *Job) Render(lib *FontLibrary) (*image.RGBA, error) {
+	var background image.Image
+	if j.Background != "" {
+		file, err := os.Open(j.path(j.Background))
+		if err != nil {
+			return nil, fmt.Errorf("Unable to open background: %w", err)
+		}
+		defer file.Close()
+
+		background, _, err = image.Decode(file)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to decode background %s: %w", j.Background, err)
+		}
+	}
+
+	bounds := image.Rect(0, 0, j.Width, j.Height)
+	if background != nil && (j.Width <= 0 || j.Height <= 0) {
+		bounds = image.Rect(0, 0, background.Bounds().Dx(), background.Bounds().Dy())
+	}
+
+	if bounds.Empty() {
+		return nil, fmt.Errorf("job has no background and no page size")
+	}
+
+	page := image.NewRGBA(bounds)
+	draw.Draw(page, bounds, image.White, image.Point{}, draw.Src)
+	if background != nil {
+		draw.Draw(page, bounds, background, background.Bounds().Min, draw.Over)
+	}
+
+	for i, item := range j.Items {
+		name := item.Font
+		if alias, ok := j.Fonts[name]; ok {
+			name = alias
+		}
+
+		font, err := lib.Font(name)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+
+		cl, err := parseColor(item.Color)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+
+		// Library fonts are shared, so the grid is turned on for a copy.
+		if item.Grid && !font.Grid {
+			grid := *font
+			grid.Grid = true
+			font = &grid
+		}
+
+		for l, line := range strings.Split(item.Text, "\n") {
+			text := line
+			pt := font.CellOrigin(image.Pt(item.X, item.Y), item.Column, l)
+			switch item.Align {
+			case AlignCenter:
+				pt.X -= font.Measure(text).Advance / 2
+			case AlignRight:
+				pt.X -= font.Measure(text).Advance
+			}
+
+			font.DrawString(page, pt, cl, text)
+		}
+	}
+
+	return page, nil
+}
+
+// parseColor reads a hex color.  An empty string is black.
+func parseColor(s string) (color.Color, error) {
+	if s == "" {
+		return color.Black, nil
+	}
+
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "FF"
+	}
+
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 8 || err != nil {
+		return nil, fmt.Errorf("Invalid color: %q", s)
+	}
+
+	return color.NRGBA{
+		R: uint8(v >> 24),
+		G: uint8(v >> 16),
+		B: uint8(v >> 8),
+		A: uint8(v),
+	}, nil
+}
@@ -0,0 +1,98 @@
+package xeroxfont
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FontLibrary is a set of fonts looked up by name.  Names are not case
+// sensitive.
+type FontLibrary struct {
+	fonts map[string]*Font
+}
+
+func NewFontLibrary() *FontLibrary {
+	return &FontLibrary{fonts: map[string]*Font{}}
+}
+
+// LoadFontLibrary loads every .FNT file in the given directories.  Fonts are
+// named by the name in their header, or the file name if that's empty.  When
+// two files have the same name the first one loaded is kept.
+func LoadFontLibrary(dirs ...string) (*FontLibrary, error) {
+	lib := NewFontLibrary()
+	for _, dir := range dirs {
+		err := lib.LoadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return lib, nil
+}
+
+// LoadDir adds every .FNT file in dir to the library, skipping names that
+// are already in it.
+func (l *FontLibrary) LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("Unable to read font library %s: %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".fnt") {
+			continue
+		}
+
+		filename := filepath.Join(dir, entry.Name())
+		font, err := LoadFontFromFile(filename)
+		if err != nil {
+			return fmt.Errorf("Unable to load %s: %w", filename, err)
+		}
+
+		name := fieldString(font.Header.FontName[:])
+		if name == "" {
+			name = strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		}
+
+		if _, ok := l.fonts[strings.ToUpper(name)]; ok {
+			continue
+		}
+		l.fonts[strings.ToUpper(name)] = font
+	}
+
+	return nil
+}
+
+// Add puts a font in the library under the given name.
+func (l *FontLibrary) Add(name string, font *Font) error {
+	key := strings.ToUpper(name)
+	if _, ok := l.fonts[key]; ok {
+		return fmt.Errorf("font %s is already in the library", name)
+	}
+	l.fonts[key] = font
+	return nil
+}
+
+func (l *FontLibrary) Font(name string) (*Font, error) {
+	font, ok := l.fonts[strings.ToUpper(name)]
+	if !ok {
+		return nil, fmt.Errorf("font %s is not in the library", name)
+	}
+	return font, nil
+}
+
+// Names returns the name of every font in the library, sorted.
+func (l *FontLibrary) Names() []string {
+	names := []string{}
+	for name := range l.fonts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (l *FontLibrary) Len() int {
+	return len(l.fonts)
+}
@@ -0,0 +1,366 @@
+package xeroxfont
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"strings"
+)
+
+// CarriageControl is the kind of control character at the start of each
+// record in a line mode print file.
+type CarriageControl int
+
+const (
+	// No control characters.  Every record is single spaced and a form feed
+	// starts a new page.
+	CarriageNone CarriageControl = iota
+
+	// ANSI (ASA) control characters.  These move the paper before the
+	// record is printed.
+	CarriageASA
+
+	// IBM machine control codes.  These move the paper after the record is
+	// printed, or without printing for the immediate codes.
+	CarriageMachine
+)
+
+func (cc CarriageControl) String() string {
+	switch cc {
+	case CarriageNone:
+		return "none"
+	case CarriageASA:
+		return "asa"
+	case CarriageMachine:
+		return "machine"
+	}
+	return fmt.Sprintf("CarriageControl(%d)", int(cc))
+}
+
+func ParseCarriageControl(name string) (CarriageControl, error) {
+	for _, cc := range []CarriageControl{CarriageNone, CarriageASA, CarriageMachine} {
+		if strings.EqualFold(name, cc.String()) {
+			return cc, nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown carriage control: %q", name)
+}
+
+// LineModeOptions controls the page layout of PrintLineData().  Sizes are in
+// points.  Zero values are replaced with defaults.
+type LineModeOptions struct {
+	PageWidth  float64 // Defaults to 612 (US Letter)
+	PageHeight float64 // Defaults to 792 (US Letter)
+	Margin     float64 // Defaults to 36
+
+	// Resolution of the pages.  Glyphs are drawn without scaling, so this
+	// has to be DeviceDPI, the resolution of the fonts.  Defaults to
+	// DeviceDPI.
+	DPI int
+
+	// Number of lines on a page.  Lines are spread evenly down the page
+	// between the margins.  Defaults to as many lines as fit with the first
+	// font's LineSpacing.
+	LinesPerPage int
+
+	CarriageControl CarriageControl
+
+	// Each record has a table reference character after the carriage
+	// control that picks the font.  Digits select fonts 0 through 9,
+	// anything else is used as a binary index.  Indexes past the end of
+	// the font list use the first font.
+	TRC bool
+
+	// Line number, starting at 1, for each channel of the forms control
+	// buffer.  Channel 1 defaults to line 1.  Skipping to a channel without
+	// a line acts like a single space.
+	Channels map[int]int
+}
+
+func (o *LineModeOptions) setDefaults() {
+	if o.PageWidth <= 0 {
+		o.PageWidth = PageLetter.PageWidth
+	}
+
+	if o.PageHeight <= 0 {
+		o.PageHeight = PageLetter.PageHeight
+	}
+
+	if o.Margin <= 0 {
+		o.Margin = 36
+	}
+
+	if o.DPI <= 0 {
+		o.DPI = DeviceDPI
+	}
+}
+
+// ASA control characters that skip to channels 1 through 12.
+const asaChannels = "123456789ABC"
+
+type linePrinter struct {
+	fonts []*Font
+	opts  LineModeOptions
+	emit  func(page int, img *image.Gray) error
+
+	left, top, pitch, lines int
+
+	img     *image.Gray
+	page    int
+	line    int  // current line, -1 is above the first line
+	inked   bool // something was printed on the current line
+	printed bool // something was printed on the current page
+}
+
+// PrintLineData reads a line mode print file and renders each page at
+// DeviceDPI with DrawString().  Records are separated by newlines.
+// The text of each record is drawn as character codes, one per byte, so it is
+// in the fonts' own character set: code page 1252, or the font's Encoding if
+// it has one.  Pages are passed to emit as they are finished, numbered from 1.
+func PrintLineData(r io.Reader, fonts []*Font, opts LineModeOptions, emit func(page int, img *image.Gray) error) error {
+	if len(fonts) == 0 {
+		return fmt.Errorf("no fonts given")
+	}
+
+	opts.setDefaults()
+	if opts.DPI != DeviceDPI {
+		return fmt.Errorf("DPI must be %d, not %d", DeviceDPI, opts.DPI)
+	}
+
+	p := &linePrinter{
+		fonts: fonts,
+		opts: opts,
+		emit: emit,
+		line: -1,
+	}
+
+	width := int(opts.PageWidth * float64(opts.DPI) / 72)
+	height := int(opts.PageHeight * float64(opts.DPI) / 72)
+	margin := int(opts.Margin * float64(opts.DPI) / 72)
+	p.left, p.top = margin, margin
+	usable := height - margin*2
+
+	if opts.LinesPerPage > 0 {
+		p.lines = opts.LinesPerPage
+		p.pitch = usable / opts.LinesPerPage
+	} else {
+		p.pitch = int(fonts[0].Header.LineSpacing)
+		if p.pitch <= 0 {
+			return fmt.Errorf("first font has no LineSpacing")
+		}
+		p.lines = usable / p.pitch
+	}
+
+	if p.lines <= 0 || p.pitch <= 0 {
+		return fmt.Errorf("no room for any lines between the margins")
+	}
+
+	p.img = image.NewGray(image.Rect(0, 0, width, height))
+	p.clear()
+
+	if opts.CarriageControl == CarriageMachine {
+		p.line = 0
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		record := strings.TrimSuffix(scanner.Text(), "\r")
+
+		var err error
+		switch opts.CarriageControl {
+		case CarriageASA:
+			err = p.asa(record)
+		case CarriageMachine:
+			err = p.machine(record)
+		default:
+			err = p.plain(record)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("Error reading line data: %w", err)
+	}
+
+	if p.printed {
+		return p.eject()
+	}
+	return nil
+}
+
+func (p *linePrinter) plain(record string) error {
+	parts := strings.Split(record, "\f")
+	for i, part := range parts {
+		if i > 0 {
+			if p.printed {
+				if err := p.eject(); err != nil {
+					return err
+				}
+			}
+			p.line = -1
+		}
+
+		if part == "" && len(parts) > 1 {
+			continue
+		}
+
+		if err := p.space(1); err != nil {
+			return err
+		}
+		p.print(part)
+	}
+	return nil
+}
+
+func (p *linePrinter) asa(record string) error {
+	if record == "" {
+		return p.space(1)
+	}
+
+	var err error
+	switch cc := record[0]; cc {
+	case ' ':
+		err = p.space(1)
+	case '0':
+		err = p.space(2)
+	case '-':
+		err = p.space(3)
+	case '+':
+		if p.line < 0 {
+			p.line = 0
+		}
+	default:
+		channel := strings.IndexByte(asaChannels, cc)
+		if channel == -1 {
+			return fmt.Errorf("Unknown ASA control character: %q", cc)
+		}
+		err = p.skip(channel+1)
+	}
+
+	if err != nil {
+		return err
+	}
+	p.print(record[1:])
+	return nil
+}
+
+func (p *linePrinter) machine(record string) error {
+	if record == "" {
+		return nil
+	}
+
+	// The low three bits say whether to print, the next five say how far
+	// to move.  Spacing uses 0 to 3 lines and skips use 16 plus the
+	// channel.
+	cc := record[0]
+	switch cc & 0x07 {
+	case 0x01:
+		p.print(record[1:])
+	case 0x03:
+	default:
+		return fmt.Errorf("Unknown machine control code: $%02X", cc)
+	}
+
+	move := int(cc >> 3)
+	switch {
+	case move <= 3:
+		return p.space(move)
+	case move >= 17 && move <= 28:
+		return p.skip(move-16)
+	}
+	return fmt.Errorf("Unknown machine control code: $%02X", cc)
+}
+
+// space moves the paper down count lines, starting a new page when it runs
+// off the bottom.
+func (p *linePrinter) space(count int) error {
+	if count == 0 {
+		return nil
+	}
+
+	p.line += count
+	p.inked = false
+	for p.line >= p.lines {
+		if err := p.eject(); err != nil {
+			return err
+		}
+		p.line -= p.lines
+	}
+	return nil
+}
+
+// skip moves the paper to the channel's line, starting a new page if the
+// line has already been passed.
+func (p *linePrinter) skip(channel int) error {
+	target, ok := p.opts.Channels[channel]
+	if !ok && channel == 1 {
+		target, ok = 1, true
+	}
+
+	if !ok || target < 1 || target > p.lines {
+		return p.space(1)
+	}
+
+	target--
+	if target < p.line || (target == p.line && p.inked) {
+		if err := p.eject(); err != nil {
+			return err
+		}
+	}
+
+	if target != p.line {
+		p.inked = false
+	}
+	p.line = target
+	return nil
+}
+
+func (p *linePrinter) print(record string) {
+	font := p.fonts[0]
+	if p.opts.TRC && len(record) > 0 {
+		idx := int(record[0])
+		if record[0] >= '0' && record[0] <= '9' {
+			idx = int(record[0] - '0')
+		}
+		if idx < len(p.fonts) {
+			font = p.fonts[idx]
+		}
+		record = record[1:]
+	}
+
+	codes := make([]rune, len(record))
+	for i := 0; i < len(record); i++ {
+		codes[i] = rune(record[i])
+	}
+
+	// Every font shares the first font's baseline, so text in different
+	// fonts on the same line stays lined up.
+	baseline := p.top + max(p.line, 0)*p.pitch + int(p.fonts[0].Header.DistanceAbove)
+	font.drawCodes(p.img, image.Pt(p.left, baseline), color.Black, codes)
+	p.inked = true
+	p.printed = true
+}
+
+func (p *linePrinter) eject() error {
+	p.page++
+	err := p.emit(p.page, p.img)
+	if err != nil {
+		return err
+	}
+
+	p.img = image.NewGray(p.img.Rect)
+	p.clear()
+	p.inked = false
+	p.printed = false
+	return nil
+}
+
+func (p *linePrinter) clear() {
+	draw.Draw(p.img, p.img.Rect, image.White, image.Point{}, draw.Src)
+}
@@ -0,0 +1,68 @@
+package xeroxfont
+
+import (
+	"image"
+	"strings"
+)
+
+// TextMetrics describes the size of a block of text.  All coordinates are
+// relative to the start of the first line's baseline, with Y increasing
+// downwards like DrawString().
+type TextMetrics struct {
+	// Width of the widest line, using each character's CellWidth, or
+	// FixedWidth for each character on the grid.
+	Advance int
+
+	// Bounding box of the pixels that would be drawn.  Empty if no pixels
+	// are drawn.
+	Ink image.Rectangle
+
+	// Ink above the first line's baseline and below the last line's
+	// baseline.  These can be negative if the ink doesn't reach the
+	// baseline.
+	Ascent  int
+	Descent int
+
+	// Advance width of each line.
+	LineWidths []int
+}
+
+// Measure returns the metrics of text as it would be drawn by DrawString() or
+// Render().  Lines are separated by a newline and are LineSpacing pixels
+// apart.  Text is Unicode and goes through the font's Encoding like
+// DrawString().  Characters that aren't in the font are skipped, unless the
+// font is laid out on a grid where they take up a cell.
+func (f *Font) Measure(text string) TextMetrics {
+	m := TextMetrics{}
+	lines := strings.Split(text, "\n")
+
+	for i, line := range lines {
+		baseline := i * int(f.Header.LineSpacing)
+		offset := 0
+
+		for _, r := range []rune(line) {
+			c, advance := f.advance(r)
+			if c == nil {
+				offset += advance
+				continue
+			}
+
+			ink := c.InkBounds()
+			if !ink.Empty() {
+				top := baseline + int(f.Header.DistanceBelow) - c.Height() - c.BlanksLeft
+				m.Ink = m.Ink.Union(ink.Add(image.Pt(offset, top)))
+			}
+			offset += advance
+		}
+
+		m.LineWidths = append(m.LineWidths, offset)
+		m.Advance = max(m.Advance, offset)
+	}
+
+	if !m.Ink.Empty() {
+		m.Ascent = -m.Ink.Min.Y
+		m.Descent = m.Ink.Max.Y - (len(lines)-1)*int(f.Header.LineSpacing)
+	}
+
+	return m
+}
@@ -42,6 +42,7 @@ func (m CharacterMeta) Character(reader io.ReadSeeker, eot int64) (*Character, e
 		GlyphOffset: int(m.GlyphOffset),
 		CellWidth: int(m.CellWidth),
 		BitmapSize: m.BitmapSize,
+		Unknown: m.Unknown,
 	}
 
 	c.IsSpace = m.IsSpace()
@@ -58,20 +59,20 @@ func (m CharacterMeta) Character(reader io.ReadSeeker, eot int64) (*Character, e
 	//c.height = c.Height()
 	//fmt.Fprintf(os.Stderr, "[meta char] 0x%06X: %s\n", currentOffset, m)
 
-	size := c.Width()*(c.Height()/8)
+	// Glyph data is stored as 16-bit words.  Odd sized glyphs have their
+	// last byte in the high half of the final word, so read the whole word.
+	size := glyphSize(c.Width(), c.Height())
 	c.glyph = make([]byte, size)
-	_, err = reader.Read(c.glyph)
+	n, err := io.ReadFull(reader, c.glyph)
+	if err == io.ErrUnexpectedEOF && n == c.Width()*(c.Height()/8) {
+		// Glyph data ends at EOF without the padding byte.
+		err = nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("error reading glyph bytes at offset %d with length of %d (%dx%d): %w", currentOffset, len(c.glyph), c.Width(), c.Height(), err)
 	}
 
-	if len(c.glyph)-1 % 2 != 0 {
-		c.glyph = append(c.glyph, 0x00)
-	}
-
-	for i := 0; i < len(c.glyph)-1; i+=2 {
-		c.glyph[i], c.glyph[i+1] = c.glyph[i+1], c.glyph[i]
-	}
+	swapWords(c.glyph)
 
 	return c, nil
 }
@@ -0,0 +1,325 @@
+package xeroxfont
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// PDFOptions controls the page layout of WritePDF().  Sizes are in points.
+// Zero values are replaced with defaults.
+type PDFOptions struct {
+	PageWidth  float64 // Defaults to 612 (US Letter)
+	PageHeight float64 // Defaults to 792 (US Letter)
+	Margin     float64 // Defaults to 36
+
+	// Resolution of the font.  Defaults to DeviceDPI.
+	DPI int
+
+	// Break lines that are too long for the page at spaces.
+	Wrap bool
+}
+
+var (
+	PageLetter = PDFOptions{PageWidth: 612, PageHeight: 792}
+	PageA4     = PDFOptions{PageWidth: 595.28, PageHeight: 841.89}
+)
+
+func (o *PDFOptions) setDefaults() {
+	if o.PageWidth <= 0 {
+		o.PageWidth = PageLetter.PageWidth
+	}
+
+	if o.PageHeight <= 0 {
+		o.PageHeight = PageLetter.PageHeight
+	}
+
+	if o.Margin <= 0 {
+		o.Margin = 36
+	}
+
+	if o.DPI <= 0 {
+		o.DPI = DeviceDPI
+	}
+}
+
+// WritePDF lays out text on as many pages as needed and writes it as a PDF.
+// The font is embedded as a Type 3 font with one image mask per glyph, so
+// the output matches the printer's pixels at any zoom and the text can be
+// searched and copied.
+func (f *Font) WritePDF(w io.Writer, text string, opts PDFOptions) error {
+	opts.setDefaults()
+
+	// One glyph pixel in points
+	px := 72 / float64(opts.DPI)
+	lineHeight := float64(f.Header.LineSpacing) * px
+	usableWidth := int((opts.PageWidth - 2*opts.Margin) / px)
+
+	if lineHeight <= 0 {
+		return fmt.Errorf("font has no line spacing")
+	}
+
+	perPage := int((opts.PageHeight - 2*opts.Margin - float64(f.Header.DistanceAbove)*px) / lineHeight) + 1
+	if perPage < 1 {
+		return fmt.Errorf("page is too small for the font")
+	}
+
+	lines := []string{}
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if opts.Wrap {
+			lines = append(lines, f.wrapLine(line, usableWidth)...)
+		} else {
+			lines = append(lines, line)
+		}
+	}
+
+	pdf := &pdfWriter{}
+	catalog := pdf.reserve()
+	pages := pdf.reserve()
+	font := f.pdfFont(pdf)
+
+	pageIds := []int{}
+	for start := 0; start < len(lines); start += perPage {
+		end := min(start+perPage, len(lines))
+
+		content := &bytes.Buffer{}
+		fmt.Fprintf(content, "BT\n/F1 %.4f Tf\n", float64(f.Header.PixelHeight)*px)
+		for i, line := range lines[start:end] {
+			y := opts.PageHeight - opts.Margin - float64(f.Header.DistanceAbove)*px - float64(i)*lineHeight
+			fmt.Fprintf(content, "1 0 0 1 %.4f %.4f Tm <%s> Tj\n", opts.Margin, y, f.pdfHexString(line))
+		}
+		fmt.Fprintln(content, "ET")
+
+		stream := pdf.addStream("", content.Bytes())
+		pageIds = append(pageIds, pdf.add(fmt.Sprintf(
+			"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>",
+			pages, opts.PageWidth, opts.PageHeight, font, stream,
+		)))
+	}
+
+	kids := []string{}
+	for _, id := range pageIds {
+		kids = append(kids, fmt.Sprintf("%d 0 R", id))
+	}
+
+	pdf.set(pages, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+	pdf.set(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pages))
+
+	return pdf.write(w, catalog)
+}
+
+func (f *Font) WritePDFFile(filename, text string, opts PDFOptions) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("unable to create %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	err = f.WritePDF(file, text, opts)
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// pdfHexString encodes text as character codes.  Characters that aren't in
+// the font are dropped.
+func (f *Font) pdfHexString(text string) string {
+	sb := &strings.Builder{}
+	for _, r := range text {
+		code, ok := f.CodeFor(r)
+		if !ok || code > 0xFF {
+			continue
+		}
+		fmt.Fprintf(sb, "%02X", code)
+	}
+	return sb.String()
+}
+
+// wrapLine breaks a line at spaces so each piece is at most width pixels
+// wide.  Words wider than width are left on their own line.
+func (f *Font) wrapLine(line string, width int) []string {
+	words := strings.Split(line, " ")
+	lines := []string{}
+	current := ""
+	for _, word := range words {
+		next := word
+		if current != "" {
+			next = current + " " + word
+		}
+
+		if current != "" && f.Measure(next).Advance > width {
+			lines = append(lines, current)
+			next = word
+		}
+		current = next
+	}
+	return append(lines, current)
+}
+
+// pdfFont adds the Type 3 font objects and returns the font's object number.
+// Glyph space is in pixels, and the FontMatrix scales it so one em is
+// PixelHeight.
+func (f *Font) pdfFont(pdf *pdfWriter) int {
+	below := int(f.Header.DistanceBelow)
+	em := float64(f.Header.PixelHeight)
+	if em == 0 {
+		em = 1
+	}
+
+	procs := []string{}
+	diffs := []string{}
+	widths := []string{}
+	toUnicode := []string{}
+	bbox := [4]int{}
+
+	for code := 0; code < 256; code++ {
+		c, advance := f.codeAdvance(rune(code))
+		if c == nil {
+			widths = append(widths, "0")
+			continue
+		}
+		widths = append(widths, fmt.Sprint(advance))
+
+		name := f.glyphName(rune(code))
+		diffs = append(diffs, fmt.Sprintf("%d /%s", code, name))
+		if u, ok := f.UnicodeFor(rune(code)); ok {
+			toUnicode = append(toUnicode, fmt.Sprintf("<%02X> <%04X>", code, u))
+		}
+
+		glyph := &bytes.Buffer{}
+		if c.IsSpace {
+			fmt.Fprintf(glyph, "%d 0 0 0 0 0 d1\n", advance)
+		} else {
+			yoff := c.BlanksLeft - below
+			fmt.Fprintf(glyph, "%d 0 0 %d %d %d d1\n", advance, yoff, c.Width(), yoff+c.Height())
+			fmt.Fprintf(glyph, "q %d 0 0 %d 0 %d cm\n", c.Width(), c.Height(), yoff)
+			fmt.Fprintf(glyph, "BI /IM true /W %d /H %d /D [1 0] /F /AHx ID\n", c.Width(), c.Height())
+			glyph.WriteString(c.maskHex())
+			fmt.Fprintln(glyph, ">\nEI Q")
+
+			bbox[1] = min(bbox[1], yoff)
+			bbox[2] = max(bbox[2], c.Width())
+			bbox[3] = max(bbox[3], yoff+c.Height())
+		}
+
+		id := pdf.addStream("", glyph.Bytes())
+		procs = append(procs, fmt.Sprintf("/%s %d 0 R", name, id))
+	}
+
+	cmap := &bytes.Buffer{}
+	fmt.Fprintln(cmap, "/CIDInit /ProcSet findresource begin 12 dict begin begincmap")
+	fmt.Fprintln(cmap, "/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def")
+	fmt.Fprintln(cmap, "/CMapName /Adobe-Identity-UCS def /CMapType 2 def")
+	fmt.Fprintln(cmap, "1 begincodespacerange <00> <FF> endcodespacerange")
+	for i := 0; i < len(toUnicode); i += 100 {
+		chunk := toUnicode[i:min(i+100, len(toUnicode))]
+		fmt.Fprintf(cmap, "%d beginbfchar\n%s\nendbfchar\n", len(chunk), strings.Join(chunk, "\n"))
+	}
+	fmt.Fprintln(cmap, "endcmap CMapName currentdict /CMap defineresource pop end end")
+
+	charProcs := pdf.add("<< " + strings.Join(procs, " ") + " >>")
+	encoding := pdf.add("<< /Type /Encoding /Differences [" + strings.Join(diffs, " ") + "] >>")
+	cmapId := pdf.addStream("", cmap.Bytes())
+
+	return pdf.add(fmt.Sprintf(
+		"<< /Type /Font /Subtype /Type3 /Name /%s /FontBBox [%d %d %d %d] /FontMatrix [%.6f 0 0 %.6f 0 0] /CharProcs %d 0 R /Encoding %d 0 R /FirstChar 0 /LastChar 255 /Widths [%s] /ToUnicode %d 0 R /Resources << >> >>",
+		pdfName(fieldString(f.Header.FontName[:])),
+		bbox[0], bbox[1], bbox[2], bbox[3],
+		1/em, 1/em,
+		charProcs, encoding,
+		strings.Join(widths, " "),
+		cmapId,
+	))
+}
+
+// maskHex returns the glyph as rows of hex digits, top to bottom, with each
+// row padded to a full byte.
+func (c *Character) maskHex() string {
+	sb := &strings.Builder{}
+	for y := 0; y < c.Height(); y++ {
+		for x := 0; x < c.Width(); x += 8 {
+			b := 0
+			for i := 0; i < 8; i++ {
+				b <<= 1
+				if c.Pixel(x+i, y) {
+					b |= 1
+				}
+			}
+			fmt.Fprintf(sb, "%02X", b)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func pdfName(name string) string {
+	if name == "" {
+		return "XeroxFont"
+	}
+
+	sb := &strings.Builder{}
+	for _, r := range name {
+		if r <= ' ' || r > '~' || strings.ContainsRune("()<>[]{}/%#", r) {
+			fmt.Fprintf(sb, "#%02X", r & 0xFF)
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// pdfWriter collects numbered objects and writes them with a cross-reference
+// table.
+type pdfWriter struct {
+	objects [][]byte
+}
+
+// reserve returns an object number to be filled in later with set().
+func (p *pdfWriter) reserve() int {
+	p.objects = append(p.objects, nil)
+	return len(p.objects)
+}
+
+func (p *pdfWriter) set(id int, obj string) {
+	p.objects[id-1] = []byte(obj)
+}
+
+func (p *pdfWriter) add(obj string) int {
+	p.objects = append(p.objects, []byte(obj))
+	return len(p.objects)
+}
+
+func (p *pdfWriter) addStream(dict string, data []byte) int {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "<< %s /Length %d >>\nstream\n", dict, len(data))
+	buf.Write(data)
+	buf.WriteString("\nendstream")
+	p.objects = append(p.objects, buf.Bytes())
+	return len(p.objects)
+}
+
+func (p *pdfWriter) write(w io.Writer, root int) error {
+	buf := &bytes.Buffer{}
+	buf.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	offsets := make([]int, len(p.objects))
+	for i, obj := range p.objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(buf, "%d 0 obj\n", i+1)
+		buf.Write(obj)
+		buf.WriteString("\nendobj\n")
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(buf, "xref\n0 %d\n0000000000 65535 f \n", len(p.objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(buf, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(p.objects)+1, root, xref)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
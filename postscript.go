@@ -0,0 +1,117 @@
+package xeroxfont
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PostScript returns the font as a PostScript Type 3 font program.  Glyph
+// space is in device pixels and the FontMatrix scales it so one em is
+// PixelHeight.  At DeviceDPI the font should be scaled to PixelHeight * 72 /
+// 300 points to match the printer pixel for pixel.
+func (f *Font) PostScript() string {
+	sb := &strings.Builder{}
+	name := f.psName()
+	below := int(f.Header.DistanceBelow)
+
+	em := float64(f.Header.PixelHeight)
+	if em == 0 {
+		em = 1
+	}
+
+	chars := []*Character{}
+	bbox := [4]int{}
+	for _, r := range f.sortedRunes() {
+		if r < 0 || r > 255 {
+			continue
+		}
+
+		c := f.Characters[r]
+		chars = append(chars, c)
+		if !c.IsSpace {
+			yoff := c.BlanksLeft - below
+			bbox[1] = min(bbox[1], yoff)
+			bbox[2] = max(bbox[2], c.Width())
+			bbox[3] = max(bbox[3], yoff+c.Height())
+		}
+	}
+
+	fmt.Fprintln(sb, "%!PS-Adobe-3.0 Resource-Font")
+	fmt.Fprintf(sb, "%%%%Title: %s\n", name)
+	fmt.Fprintf(sb, "%%%%BeginResource: font %s\n", name)
+	fmt.Fprintf(sb, "%% Natural size: %.2f points at %d dpi\n", em*72/float64(DeviceDPI), DeviceDPI)
+	fmt.Fprintln(sb, "10 dict begin")
+	fmt.Fprintln(sb, "/FontType 3 def")
+	fmt.Fprintf(sb, "/FontName /%s def\n", name)
+	fmt.Fprintf(sb, "/FontMatrix [%.6f 0 0 %.6f 0 0] def\n", 1/em, 1/em)
+	fmt.Fprintf(sb, "/FontBBox [%d %d %d %d] def\n", bbox[0], bbox[1], bbox[2], bbox[3])
+
+	fmt.Fprintln(sb, "/Encoding 256 array def")
+	fmt.Fprintln(sb, "0 1 255 { Encoding exch /.notdef put } for")
+	for _, c := range chars {
+		fmt.Fprintf(sb, "Encoding %d /%s put\n", c.Value, f.glyphName(c.Value))
+	}
+
+	// Each entry is [CellWidth width height yoffset <bitmap>]
+	fmt.Fprintf(sb, "/CharData %d dict def\n", len(chars)+1)
+	fmt.Fprintln(sb, "CharData begin")
+	fmt.Fprintln(sb, "/.notdef [0 0 0 0 <>] def")
+	for _, c := range chars {
+		if c.IsSpace {
+			fmt.Fprintf(sb, "/%s [%d 0 0 0 <>] def\n", f.glyphName(c.Value), c.CellWidth)
+			continue
+		}
+
+		fmt.Fprintf(sb, "/%s [%d %d %d %d <\n%s>] def\n",
+			f.glyphName(c.Value),
+			c.CellWidth,
+			c.Width(),
+			c.Height(),
+			c.BlanksLeft - below,
+			c.maskHex(),
+		)
+	}
+	fmt.Fprintln(sb, "end")
+
+	fmt.Fprintln(sb, `/BuildGlyph {
+	8 dict begin
+	/name exch def
+	/font exch def
+	font /CharData get dup name known { name } { /.notdef } ifelse get
+	aload pop
+	/data exch def /yoff exch def /h exch def /w exch def /cw exch def
+	cw 0 0 yoff w yoff h add setcachedevice
+	w 0 gt {
+		0 yoff translate
+		w h true [1 0 0 -1 0 h] { data } imagemask
+	} if
+	end
+} bind def
+/BuildChar {
+	1 index /Encoding get exch get
+	1 index /BuildGlyph get exec
+} bind def`)
+
+	fmt.Fprintln(sb, "currentdict")
+	fmt.Fprintln(sb, "end")
+	fmt.Fprintf(sb, "/%s exch definefont pop\n", name)
+	sb.WriteString("%%EndResource\n")
+	sb.WriteString("%%EOF\n")
+	return sb.String()
+}
+
+// psName returns the font name with characters that aren't allowed in a
+// PostScript name replaced.
+func (f *Font) psName() string {
+	name := fieldString(f.Header.FontName[:])
+	if name == "" {
+		return "XeroxFont"
+	}
+
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || strings.ContainsRune("()<>[]{}/%", r) {
+			return '_'
+		}
+		return r
+	}, name)
+}
@@ -0,0 +1,83 @@
+package xeroxfont
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Resolution of the printers these fonts are made for.
+const DeviceDPI int = 300
+
+// RenderPreview renders text at the device resolution and scales it down to
+// dpi with a box filter.  The result is anti-aliased black text on a white
+// background, suitable for displaying on a screen.
+func (f *Font) RenderPreview(text string, dpi int) *image.Gray {
+	return Downsample(f.Render(color.Black, text), DeviceDPI, dpi)
+}
+
+// Downsample scales a device resolution image down from srcDPI to dstDPI.
+// The alpha channel of src is used as ink coverage and each output pixel is
+// the average coverage of the source pixels it covers, including partial
+// pixels.  Ink is black on a white background.  If dstDPI is not lower than
+// srcDPI the image is converted without scaling.
+func Downsample(src image.Image, srcDPI, dstDPI int) *image.Gray {
+	scale := 1.0
+	if dstDPI > 0 && dstDPI < srcDPI {
+		scale = float64(srcDPI) / float64(dstDPI)
+	}
+
+	b := src.Bounds()
+	w := int(math.Ceil(float64(b.Dx()) / scale))
+	h := int(math.Ceil(float64(b.Dy()) / scale))
+	dst := image.NewGray(image.Rect(0, 0, w, h))
+
+	cols := boxSpans(w, b.Dx(), scale)
+	rows := boxSpans(h, b.Dy(), scale)
+
+	for oy, row := range rows {
+		for ox, col := range cols {
+			sum, area := 0.0, 0.0
+			for _, sy := range row {
+				for _, sx := range col {
+					_, _, _, a := src.At(b.Min.X+sx.pos, b.Min.Y+sy.pos).RGBA()
+					weight := sx.weight * sy.weight
+					sum += weight * float64(a) / 0xFFFF
+					area += weight
+				}
+			}
+
+			coverage := 0.0
+			if area > 0 {
+				coverage = sum / area
+			}
+			dst.SetGray(ox, oy, color.Gray{Y: uint8(math.Round(255 * (1 - coverage)))})
+		}
+	}
+
+	return dst
+}
+
+type boxSample struct {
+	pos    int
+	weight float64
+}
+
+// boxSpans returns, for each of the count output pixels, the source pixels
+// it covers and how much of each is covered.  size is the number of source
+// pixels.
+func boxSpans(count, size int, scale float64) [][]boxSample {
+	spans := make([][]boxSample, count)
+	for i := range spans {
+		start := float64(i) * scale
+		end := math.Min(start+scale, float64(size))
+
+		for p := int(start); float64(p) < end; p++ {
+			weight := math.Min(end, float64(p+1)) - math.Max(start, float64(p))
+			if weight > 0 {
+				spans[i] = append(spans[i], boxSample{p, weight})
+			}
+		}
+	}
+	return spans
+}
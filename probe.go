@@ -0,0 +1,212 @@
+package xeroxfont
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Format is the layout of the character metadata table.
+type Format int
+
+const (
+	FormatUnknown Format = iota
+	Format9700
+	Format5Word
+)
+
+func (f Format) String() string {
+	switch f {
+	case Format9700:
+		return "9700"
+	case Format5Word:
+		return "5Word"
+	}
+	return "Unknown"
+}
+
+func (f Format) MarshalText() ([]byte, error) {
+	return []byte(f.String()), nil
+}
+
+func (f *Format) UnmarshalText(text []byte) error {
+	var err error
+	*f, err = ParseFormat(string(text))
+	return err
+}
+
+// ProbeResult is a summary of a font file that only requires reading the
+// headers and the metadata table.  No glyph bitmaps are decoded.
+type ProbeResult struct {
+	Format         Format
+	Confidence     float64
+	HasExtraHeader bool
+	Orientation    Orientation
+	FontType       byte
+	PixelHeight    int
+	Name           string
+	Revision       string
+	Version        string
+	Library        string
+
+	// Number of characters with a glyph bitmap (ie, not spacing characters).
+	GlyphCount int
+
+	// Number of entries in the metadata table.
+	MetaCount int
+}
+
+func (p ProbeResult) MarshalJSON() ([]byte, error) {
+	data := struct {
+		Format         string
+		Confidence     float64
+		HasExtraHeader bool
+		Orientation    string
+		FontType       string
+		PixelHeight    int
+		Name           string
+		Revision       string
+		Version        string
+		Library        string
+		GlyphCount     int
+		MetaCount      int
+	}{
+		Format:         p.Format.String(),
+		Confidence:     p.Confidence,
+		HasExtraHeader: p.HasExtraHeader,
+		Orientation:    p.Orientation.String(),
+		FontType:       fontTypeName(p.FontType),
+		PixelHeight:    p.PixelHeight,
+		Name:           p.Name,
+		Revision:       p.Revision,
+		Version:        p.Version,
+		Library:        p.Library,
+		GlyphCount:     p.GlyphCount,
+		MetaCount:      p.MetaCount,
+	}
+
+	return json.Marshal(data)
+}
+
+// Probe reads just enough of a font to describe it.  The reader is left at an
+// undefined position.  The format is detected; see DetectFormat.
+func Probe(reader io.ReadSeeker) (*ProbeResult, error) {
+	return ProbeWithOptions(reader, LoadOptions{})
+}
+
+// ProbeWithOptions is Probe with the same options as LoadFontWithOptions.  A
+// forced format is used as-is and has a Confidence of zero.
+func ProbeWithOptions(reader io.ReadSeeker, opts LoadOptions) (*ProbeResult, error) {
+	extra, header, err := readHeaders(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	metaStart := int64(binary.Size(header) + 256)
+	if extra != nil {
+		metaStart += int64(binary.Size(extra))
+	}
+
+	det := &Detection{Format: opts.Format}
+	if det.Format == FormatUnknown {
+		det, err = detectFormat(reader, header, metaStart)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = reader.Seek(metaStart, io.SeekStart)
+	if err != nil {
+		return nil, fmt.Errorf("Error seeking to metadata table: %w", err)
+	}
+
+	result := &ProbeResult{
+		HasExtraHeader: extra != nil,
+		Orientation:    header.Orientation,
+		FontType:       header.FontType,
+		PixelHeight:    int(header.PixelHeight),
+		Name:           fieldString(header.FontName[:]),
+		Revision:       fieldString(header.Revision[:]),
+		Version:        fieldString(header.Version[:]),
+		Library:        fieldString(header.Library[:]),
+		MetaCount:      header.MetaCount(),
+		Format:         det.Format,
+		Confidence:     det.Confidence,
+	}
+
+	var meta []CharacterMeta
+	switch det.Format {
+	case Format9700:
+		meta, err = MetaFrom9700(reader, result.MetaCount)
+	case Format5Word:
+		meta, err = MetaFrom5Word(reader, result.MetaCount)
+	default:
+		return nil, fmt.Errorf("Unable to detect metadata format")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing metadata: %w", err)
+	}
+
+	for _, m := range meta {
+		if !m.IsSpace() {
+			result.GlyphCount++
+		}
+	}
+
+	return result, nil
+}
+
+func ProbeFile(filename string) (*ProbeResult, error) {
+	return ProbeFileWithOptions(filename, LoadOptions{})
+}
+
+func ProbeFileWithOptions(filename string, opts LoadOptions) (*ProbeResult, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ProbeWithOptions(file, opts)
+}
+
+// readHeaders reads the optional extra header and the main header, leaving
+// the reader at the start of the width table.  extra is nil if the file does
+// not have an extra header.
+func readHeaders(reader io.ReadSeeker) (*ExtraHeader, *FontHeader, error) {
+	_, err := reader.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error seeking to start: %w", err)
+	}
+
+	var val byte
+	err = binary.Read(reader, binary.LittleEndian, &val)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error reading first byte: %w", err)
+	}
+
+	_, err = reader.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error seeking to start: %w", err)
+	}
+
+	var extra *ExtraHeader
+	if !IsOrientation(val) {
+		extra = &ExtraHeader{}
+		err = binary.Read(reader, binary.LittleEndian, extra)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Error reading extra header: %w", err)
+		}
+	}
+
+	header := &FontHeader{}
+	err = binary.Read(reader, binary.LittleEndian, header)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error reading main header: %w", err)
+	}
+
+	return extra, header, nil
+}
@@ -0,0 +1,211 @@
+package xeroxfont
+
+import (
+	"fmt"
+	"image"
+	"sort"
+)
+
+// Rotate returns a copy of a Portrait font stored for a different orientation.
+//
+// Glyphs are always stored in the printer's frame, with the page rotated
+// underneath them.  For every orientation the bitmap's X axis starts at the
+// cell's left edge (in the printer's frame) and BlanksLeft is the distance
+// from the cell's bottom edge to the bottom of the bitmap.  Rotating a font
+// only moves the cell's edges around:
+//
+//	Landscape:          rotated 90 degrees counter-clockwise.  X starts at
+//	                    the ascender line and the cell's bottom is the pen
+//	                    position.
+//	Inverted Portrait:  rotated 180 degrees.  X starts at the far end of the
+//	                    cell and the cell's bottom is the ascender line.
+//	Inverted Landscape: rotated 90 degrees clockwise.  X starts at the
+//	                    descender line and the cell's bottom is the far end of
+//	                    the cell.
+//
+// In the inverted orientations the far end of the cell is offset by half of
+// DistanceLeading.  This comes from the samples rather than any
+// documentation: BS10N has a DistanceLeading of 6 and its inverted fonts are
+// offset by 3, and HA10N has a DistanceLeading of 0 and isn't offset.
+//
+// Rotated bitmaps are cropped to their ink.  rotate_test.go checks that every
+// glyph's ink lands in the same place in the cell as in the shipped BS10N and
+// HA10N fonts of both formats.  The bitmaps themselves aren't always the
+// same: a few of the shipped glyphs have an extra blank row or column, so
+// their size or BlanksLeft differs (9 glyphs in the 9700 BS10NI, and 11, 9,
+// and 14 in the 5Word BS10NL, BS10NI, and BS10NJ).  Use CompareGlyphs() to
+// see the differences.
+func (f *Font) Rotate(o Orientation) (*Font, error) {
+	if f.Header.Orientation != Portrait {
+		return nil, fmt.Errorf("only portrait fonts can be rotated, not %s", f.Header.Orientation)
+	}
+
+	if !IsOrientation(byte(o)) {
+		return nil, fmt.Errorf("invalid orientation: $%02X", byte(o))
+	}
+
+	n := f.Clone()
+	n.Header.Orientation = o
+	if id, err := f.FontID(); err == nil {
+		id.Orientation = o
+		err = n.SetFontID(id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if o == Portrait {
+		return n, nil
+	}
+
+	cellHeight := int(f.Header.DistanceAbove) + int(f.Header.DistanceBelow)
+	farOffset := int(f.Header.DistanceLeading) / 2
+
+	for r, c := range n.Characters {
+		if c.IsSpace {
+			continue
+		}
+		src := f.Characters[r]
+
+		// Ink in the rotated frame.  X is from the left of the bitmap, Y is
+		// up from the bottom of the cell.
+		type point struct{ x, y int }
+		ink := []point{}
+		for y := 0; y < src.Height(); y++ {
+			for x := 0; x < src.Width(); x++ {
+				if !src.Pixel(x, y) {
+					continue
+				}
+
+				// Position in the portrait cell, with Y up from the bottom.
+				px := x
+				py := src.BlanksLeft + src.Height() - y - 1
+
+				var p point
+				switch o {
+				case Landscape:
+					p = point{cellHeight - py - 1, px}
+				case InvertedPortrait:
+					p = point{src.CellWidth - px - 1 + farOffset, cellHeight - py - 1}
+				case InvertedLandscape:
+					p = point{py, src.CellWidth - px - 1 + farOffset}
+				}
+
+				if p.x < 0 || p.y < 0 {
+					return nil, fmt.Errorf("character 0x%02X has ink outside of its cell", r)
+				}
+				ink = append(ink, p)
+			}
+		}
+
+		if len(ink) == 0 {
+			continue
+		}
+
+		maxX, minY, maxY := ink[0].x, ink[0].y, ink[0].y
+		for _, p := range ink {
+			maxX = max(maxX, p.x)
+			minY = min(minY, p.y)
+			maxY = max(maxY, p.y)
+		}
+
+		err := c.Resize(maxX+1, maxY-minY+1)
+		if err != nil {
+			return nil, fmt.Errorf("character 0x%02X: %w", r, err)
+		}
+		c.clear()
+		c.BlanksLeft = minY
+
+		for _, p := range ink {
+			c.SetPixel(p.x, c.Height()-(p.y-minY)-1, true)
+		}
+	}
+
+	return n, nil
+}
+
+// GlyphDiff is a difference between the same character in two fonts.
+type GlyphDiff struct {
+	Code rune
+
+	// True if the characters draw different pixels.  Otherwise only the
+	// size or position of the bitmap is different.
+	Ink bool
+
+	Description string
+}
+
+func (d GlyphDiff) String() string {
+	return fmt.Sprintf("0x%02X %s", d.Code, d.Description)
+}
+
+// CompareGlyphs compares every character in f with the same character in
+// other, in code order.  Ink is compared by its position in the cell, so a
+// glyph with extra blank rows or columns draws the same as one without them
+// but is still listed.
+func (f *Font) CompareGlyphs(other *Font) []GlyphDiff {
+	codes := f.sortedRunes()
+	for _, r := range other.sortedRunes() {
+		if _, ok := f.Characters[r]; !ok {
+			codes = append(codes, r)
+		}
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+
+	diffs := []GlyphDiff{}
+	for _, r := range codes {
+		c, o := f.Characters[r], other.Characters[r]
+		switch {
+		case c == nil:
+			diffs = append(diffs, GlyphDiff{Code: r, Ink: true, Description: "only in the other font"})
+			continue
+		case o == nil:
+			diffs = append(diffs, GlyphDiff{Code: r, Ink: true, Description: "missing from the other font"})
+			continue
+		}
+
+		if c.CellWidth != o.CellWidth {
+			diffs = append(diffs, GlyphDiff{Code: r, Ink: true,
+				Description: fmt.Sprintf("cell width %d, other %d", c.CellWidth, o.CellWidth)})
+		}
+
+		if c.IsSpace || o.IsSpace {
+			if c.IsSpace != o.IsSpace {
+				diffs = append(diffs, GlyphDiff{Code: r, Ink: true, Description: "spacing character in only one font"})
+			}
+			continue
+		}
+
+		inkA, inkB := c.cellInk(), o.cellInk()
+		same := len(inkA) == len(inkB)
+		for p := range inkA {
+			if !inkB[p] {
+				same = false
+				break
+			}
+		}
+
+		if !same {
+			diffs = append(diffs, GlyphDiff{Code: r, Ink: true, Description: "ink differs"})
+		} else if c.Width() != o.Width() || c.Height() != o.Height() || c.BlanksLeft != o.BlanksLeft {
+			diffs = append(diffs, GlyphDiff{Code: r, Description: fmt.Sprintf(
+				"bitmap %dx%d blanks %d, other %dx%d blanks %d",
+				c.Width(), c.Height(), c.BlanksLeft, o.Width(), o.Height(), o.BlanksLeft)})
+		}
+	}
+	return diffs
+}
+
+// cellInk returns the set pixels of the glyph with Y up from the bottom of
+// the cell.
+func (c *Character) cellInk() map[image.Point]bool {
+	ink := map[image.Point]bool{}
+	for y := 0; y < c.Height(); y++ {
+		for x := 0; x < c.Width(); x++ {
+			if c.Pixel(x, y) {
+				ink[image.Pt(x, c.BlanksLeft+c.Height()-y-1)] = true
+			}
+		}
+	}
+	return ink
+}
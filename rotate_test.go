@@ -0,0 +1,41 @@
+package xeroxfont
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestRotateSamples rotates the portrait samples and compares them with the
+// shipped fonts for the other orientations.  Only ink has to match; see
+// Rotate() for the bitmaps that are known to differ.
+func TestRotateSamples(t *testing.T) {
+	for _, format := range []string{"9700", "5word"} {
+		for _, family := range []string{"BS10N", "HA10N"} {
+			portrait, err := LoadFontFromFile(filepath.Join("sample-fonts", format, family+"P.FNT"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, o := range []Orientation{Landscape, InvertedPortrait, InvertedLandscape} {
+				name := filepath.Join("sample-fonts", format, family+string(rune(o))+".FNT")
+				t.Run(name, func(t *testing.T) {
+					want, err := LoadFontFromFile(name)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					got, err := portrait.Rotate(o)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					for _, d := range got.CompareGlyphs(want) {
+						if d.Ink {
+							t.Errorf("%s", d)
+						}
+					}
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package xeroxfont
+
+// SubsetMode decides what happens to characters that are not kept by
+// Font.Subset().
+type SubsetMode int
+
+const (
+	// Unused characters become spacing characters with their original
+	// CellWidth, so the metrics of the font don't change.
+	SubsetSpace SubsetMode = iota
+
+	// Unused characters are removed and LastCharacter is lowered to the
+	// highest kept character.  The encoder writes removed characters as
+	// empty spacing entries.
+	SubsetDrop
+)
+
+// Subset returns a copy of the font with only the given character codes.
+// Codes that are not in the font are ignored.  Use CodeFor() to find the
+// codes for Unicode text.
+func (f *Font) Subset(keep []rune, mode SubsetMode) *Font {
+	keepSet := make(map[rune]bool)
+	for _, r := range keep {
+		keepSet[r] = true
+	}
+
+	n := f.Clone()
+	last := rune(0)
+	for r, c := range n.Characters {
+		if keepSet[r] {
+			last = max(last, r)
+			continue
+		}
+
+		switch mode {
+		case SubsetDrop:
+			delete(n.Characters, r)
+		default:
+			c.SetIsSpace(true)
+		}
+	}
+
+	if mode == SubsetDrop {
+		n.Header.LastCharacter = uint16(last)
+	}
+
+	return n
+}
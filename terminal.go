@@ -0,0 +1,181 @@
+package xeroxfont
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+)
+
+type TerminalMode int
+
+const (
+	// Two pixels per character cell, stacked, using half block characters.
+	TermHalfBlock TerminalMode = iota
+
+	// Eight pixels per character cell, two wide and four tall, using
+	// braille patterns.
+	TermBraille
+)
+
+func (m TerminalMode) String() string {
+	switch m {
+	case TermHalfBlock:
+		return "halfblock"
+	case TermBraille:
+		return "braille"
+	}
+	return fmt.Sprintf("TerminalMode(%d)", int(m))
+}
+
+func ParseTerminalMode(name string) (TerminalMode, error) {
+	for _, m := range []TerminalMode{TermHalfBlock, TermBraille} {
+		if strings.EqualFold(name, m.String()) {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown terminal mode: %q", name)
+}
+
+// TerminalOptions controls how images are drawn with text by
+// TerminalImage().
+type TerminalOptions struct {
+	Mode TerminalMode
+
+	// Use 24-bit ANSI colors so gray levels show up.  Without color, pixels
+	// darker than Threshold are drawn and everything else is left blank.
+	Color bool
+
+	// Defaults to 128.
+	Threshold uint8
+}
+
+// Bits of a braille pattern for each dot, indexed by [y][x].
+var brailleDots = [4][2]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+// TerminalImage draws an image as lines of text.  Dark pixels are ink, and
+// ink is drawn in the terminal's foreground color so it shows up on dark and
+// light terminals alike.
+func TerminalImage(img image.Image, opts TerminalOptions) string {
+	if opts.Threshold == 0 {
+		opts.Threshold = 128
+	}
+
+	b := img.Bounds()
+	level := func(x, y int) uint8 {
+		if !(image.Point{x, y}.In(b)) {
+			return 0
+		}
+		return 255 - color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y
+	}
+	on := func(x, y int) bool {
+		return 255-level(x, y) < opts.Threshold
+	}
+
+	sb := &strings.Builder{}
+	switch opts.Mode {
+	case TermBraille:
+		for y := b.Min.Y; y < b.Max.Y; y += 4 {
+			for x := b.Min.X; x < b.Max.X; x += 2 {
+				pattern := rune(0x2800)
+				brightest := uint8(0)
+				for dy := 0; dy < 4; dy++ {
+					for dx := 0; dx < 2; dx++ {
+						v := level(x+dx, y+dy)
+						if opts.Color && v > 0 || on(x+dx, y+dy) {
+							pattern |= brailleDots[dy][dx]
+							brightest = max(brightest, v)
+						}
+					}
+				}
+
+				if opts.Color {
+					fmt.Fprintf(sb, "\x1b[38;2;%d;%d;%dm", brightest, brightest, brightest)
+				}
+				sb.WriteRune(pattern)
+			}
+			if opts.Color {
+				sb.WriteString("\x1b[0m")
+			}
+			sb.WriteString("\n")
+		}
+
+	default:
+		for y := b.Min.Y; y < b.Max.Y; y += 2 {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				if opts.Color {
+					top, bottom := level(x, y), level(x, y+1)
+					fmt.Fprintf(sb, "\x1b[38;2;%d;%d;%dm\x1b[48;2;%d;%d;%dm▀", top, top, top, bottom, bottom, bottom)
+					continue
+				}
+
+				switch top, bottom := on(x, y), on(x, y+1); {
+				case top && bottom:
+					sb.WriteRune('█')
+				case top:
+					sb.WriteRune('▀')
+				case bottom:
+					sb.WriteRune('▄')
+				default:
+					sb.WriteRune(' ')
+				}
+			}
+			if opts.Color {
+				sb.WriteString("\x1b[0m")
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// Upright returns the glyph turned so it reads normally, undoing the
+// rotation of fonts stored for other orientations.  Ink is black on white.
+func (c *Character) Upright(o Orientation) *image.Gray {
+	w, h := c.Width(), c.Height()
+	if c.IsSpace {
+		w, h = 0, 0
+	}
+
+	// Size and source pixel for each upright pixel.
+	uw, uh := w, h
+	src := func(x, y int) (int, int) { return x, y }
+	switch o {
+	case Landscape:
+		uw, uh = h, w
+		src = func(x, y int) (int, int) { return y, h - 1 - x }
+	case InvertedPortrait:
+		src = func(x, y int) (int, int) { return w - 1 - x, h - 1 - y }
+	case InvertedLandscape:
+		uw, uh = h, w
+		src = func(x, y int) (int, int) { return w - 1 - y, x }
+	}
+
+	img := image.NewGray(image.Rect(0, 0, uw, uh))
+	for y := 0; y < uh; y++ {
+		for x := 0; x < uw; x++ {
+			img.Pix[y*img.Stride+x] = 0xFF
+			if c.Pixel(src(x, y)) {
+				img.Pix[y*img.Stride+x] = 0
+			}
+		}
+	}
+	return img
+}
+
+// TerminalText renders text with the font, scaled down to dpi, and draws it
+// as lines of terminal text.  The text is Unicode and characters the font
+// doesn't have are left out.  Only portrait fonts can render text.
+func (f *Font) TerminalText(text string, dpi int, opts TerminalOptions) (string, error) {
+	if f.Header.Orientation != Portrait {
+		return "", fmt.Errorf("only portrait fonts can render text, not %s", f.Header.Orientation)
+	}
+
+	return TerminalImage(f.RenderPreview(text, dpi), opts), nil
+}
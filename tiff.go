@@ -0,0 +1,180 @@
+package xeroxfont
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+// TIFFWriter writes pages to a multipage bilevel TIFF.  Pixels darker than
+// middle gray are black.  Each page is PackBits compressed.
+type TIFFWriter struct {
+	w   io.Writer
+	dpi int
+
+	offset  int
+	pages   int
+	pending []byte // IFD of the last page, waiting for the next IFD's offset
+	err     error
+}
+
+const (
+	tiffShort    = 3
+	tiffLong     = 4
+	tiffRational = 5
+
+	tiffEntries = 14
+	tiffIFDSize = 2 + tiffEntries*12 + 4
+)
+
+// NewTIFFWriter starts a TIFF with pages at the given resolution.  Close()
+// must be called after the last page.
+func NewTIFFWriter(w io.Writer, dpi int) *TIFFWriter {
+	if dpi <= 0 {
+		dpi = DeviceDPI
+	}
+	return &TIFFWriter{w: w, dpi: dpi}
+}
+
+func (t *TIFFWriter) write(data []byte) {
+	if t.err != nil {
+		return
+	}
+	_, t.err = t.w.Write(data)
+	t.offset += len(data)
+}
+
+// WritePage adds a page to the file.
+func (t *TIFFWriter) WritePage(img image.Image) error {
+	if t.err != nil {
+		return t.err
+	}
+
+	bounds := img.Bounds()
+	strip := &bytes.Buffer{}
+	row := make([]byte, (bounds.Dx()+7)/8)
+	gray, isGray := img.(*image.Gray)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		clear(row)
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			var v uint8
+			if isGray {
+				v = gray.GrayAt(x, y).Y
+			} else {
+				v = color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y
+			}
+
+			if v < 0x80 {
+				i := x - bounds.Min.X
+				row[i/8] |= 0x80 >> (i % 8)
+			}
+		}
+		packBits(strip, row)
+	}
+
+	// IFDs have to start on a word boundary.
+	stripSize := strip.Len()
+	if strip.Len()%2 == 1 {
+		strip.WriteByte(0)
+	}
+
+	// The IFD follows the strip.  The first page's offset goes in the file
+	// header and every other page's goes in the previous IFD.
+	if t.pages == 0 {
+		header := []byte{'I', 'I', 42, 0, 0, 0, 0, 0}
+		binary.LittleEndian.PutUint32(header[4:], uint32(8+strip.Len()))
+		t.write(header)
+	} else {
+		binary.LittleEndian.PutUint32(t.pending[tiffIFDSize-4:], uint32(t.offset+len(t.pending)+strip.Len()))
+		t.write(t.pending)
+	}
+
+	dataOffset := t.offset
+	t.write(strip.Bytes())
+	t.pages++
+
+	ifd := &bytes.Buffer{}
+	rational := t.offset + tiffIFDSize
+	entry := func(tag, typ uint16, value uint32) {
+		binary.Write(ifd, binary.LittleEndian, []uint16{tag, typ})
+		binary.Write(ifd, binary.LittleEndian, uint32(1))
+		if typ == tiffShort {
+			binary.Write(ifd, binary.LittleEndian, []uint16{uint16(value), 0})
+		} else {
+			binary.Write(ifd, binary.LittleEndian, value)
+		}
+	}
+
+	binary.Write(ifd, binary.LittleEndian, uint16(tiffEntries))
+	entry(254, tiffLong, 2)         // NewSubfileType, one page of many
+	entry(256, tiffLong, uint32(bounds.Dx()))
+	entry(257, tiffLong, uint32(bounds.Dy()))
+	entry(258, tiffShort, 1)        // BitsPerSample
+	entry(259, tiffShort, 32773)    // Compression, PackBits
+	entry(262, tiffShort, 0)        // PhotometricInterpretation, WhiteIsZero
+	entry(273, tiffLong, uint32(dataOffset))
+	entry(277, tiffShort, 1)        // SamplesPerPixel
+	entry(278, tiffLong, uint32(bounds.Dy()))
+	entry(279, tiffLong, uint32(stripSize))
+	entry(282, tiffRational, uint32(rational))
+	entry(283, tiffRational, uint32(rational+8))
+	entry(296, tiffShort, 2)        // ResolutionUnit, inches
+
+	// PageNumber holds two shorts, this page and the unknown total.
+	binary.Write(ifd, binary.LittleEndian, []uint16{297, tiffShort})
+	binary.Write(ifd, binary.LittleEndian, uint32(2))
+	binary.Write(ifd, binary.LittleEndian, []uint16{uint16(t.pages - 1), 0})
+
+	binary.Write(ifd, binary.LittleEndian, uint32(0)) // next IFD
+	binary.Write(ifd, binary.LittleEndian, []uint32{uint32(t.dpi), 1, uint32(t.dpi), 1})
+	t.pending = ifd.Bytes()
+
+	return t.err
+}
+
+// Close writes the last page's IFD.  It does not close the underlying writer.
+func (t *TIFFWriter) Close() error {
+	if t.err != nil {
+		return t.err
+	}
+
+	if t.pages == 0 {
+		return fmt.Errorf("TIFF has no pages")
+	}
+
+	t.write(t.pending)
+	t.pending = nil
+	return t.err
+}
+
+// packBits appends one PackBits compressed row to buf.
+func packBits(buf *bytes.Buffer, row []byte) {
+	for i := 0; i < len(row); {
+		// Runs of three or more repeated bytes
+		run := 1
+		for i+run < len(row) && run < 128 && row[i+run] == row[i] {
+			run++
+		}
+
+		if run >= 3 {
+			buf.WriteByte(byte(1 - run))
+			buf.WriteByte(row[i])
+			i += run
+			continue
+		}
+
+		// Literal bytes up to the next run of three
+		start := i
+		for i < len(row) && i-start < 128 {
+			if i+2 < len(row) && row[i] == row[i+1] && row[i] == row[i+2] {
+				break
+			}
+			i++
+		}
+		buf.WriteByte(byte(i - start - 1))
+		buf.Write(row[start:i])
+	}
+}
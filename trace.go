@@ -0,0 +1,190 @@
+package xeroxfont
+
+import (
+	"fmt"
+	"strings"
+)
+
+type TraceMode int
+
+const (
+	// Follow the pixel edges exactly.  Every point is on the curve.
+	TracePixel TraceMode = iota
+
+	// Round off single pixel steps with quadratic curves, keeping the
+	// corners between longer runs square.
+	TraceSmooth
+)
+
+func (m TraceMode) String() string {
+	switch m {
+	case TracePixel:
+		return "pixel"
+	case TraceSmooth:
+		return "smooth"
+	}
+	return fmt.Sprintf("TraceMode(%d)", int(m))
+}
+
+func ParseTraceMode(name string) (TraceMode, error) {
+	for _, m := range []TraceMode{TracePixel, TraceSmooth} {
+		if strings.EqualFold(name, m.String()) {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown trace mode: %q", name)
+}
+
+// ContourPoint is a point on an outline.  Off curve points are quadratic
+// control points, the same as in TrueType.
+type ContourPoint struct {
+	X, Y    float64
+	OnCurve bool
+}
+
+// Contour is a closed outline.  Outer contours run clockwise and holes run
+// counter-clockwise.
+type Contour []ContourPoint
+
+// Directions of travel along the pixel edges, in clockwise order.  Y is up.
+var traceDirs = [4]struct{ dx, dy int }{
+	{1, 0},  // east
+	{0, -1}, // south
+	{-1, 0}, // west
+	{0, 1},  // north
+}
+
+type traceEdge struct {
+	x, y, dir int
+}
+
+// Trace converts the glyph's bitmap into closed contours.  Coordinates are in
+// pixels with the origin at the bottom left corner of the bitmap and Y going
+// up.  Spaces and empty glyphs have no contours.
+func (c *Character) Trace(mode TraceMode) []Contour {
+	if c.IsSpace {
+		return nil
+	}
+
+	w, h := c.Width(), c.Height()
+	ink := func(x, y int) bool {
+		if x < 0 || y < 0 || x >= w || y >= h {
+			return false
+		}
+		return c.Pixel(x, h-y-1)
+	}
+
+	// Every pixel side between ink and blank is an edge, directed so the
+	// ink is on the right.
+	edges := []traceEdge{}
+	unused := map[traceEdge]bool{}
+	add := func(e traceEdge) {
+		edges = append(edges, e)
+		unused[e] = true
+	}
+
+	for y := h-1; y >= 0; y-- {
+		for x := 0; x < w; x++ {
+			if !ink(x, y) {
+				continue
+			}
+			if !ink(x, y+1) {
+				add(traceEdge{x, y+1, 0})
+			}
+			if !ink(x+1, y) {
+				add(traceEdge{x+1, y+1, 1})
+			}
+			if !ink(x, y-1) {
+				add(traceEdge{x+1, y, 2})
+			}
+			if !ink(x-1, y) {
+				add(traceEdge{x, y, 3})
+			}
+		}
+	}
+
+	contours := []Contour{}
+	for _, start := range edges {
+		if !unused[start] {
+			continue
+		}
+
+		// Walk the loop, keeping only the points where it turns.
+		points := [][2]int{}
+		e := start
+		for {
+			delete(unused, e)
+			nx, ny := e.x+traceDirs[e.dir].dx, e.y+traceDirs[e.dir].dy
+
+			// Where two pixels touch diagonally there are two ways out of
+			// a vertex.  Turning right first keeps them apart.
+			var next traceEdge
+			for _, turn := range []int{1, 0, 3} {
+				next = traceEdge{nx, ny, (e.dir+turn) % 4}
+				if unused[next] || next == start {
+					break
+				}
+			}
+
+			if next.dir != e.dir {
+				points = append(points, [2]int{nx, ny})
+			}
+
+			if next == start {
+				break
+			}
+			e = next
+		}
+
+		if mode == TraceSmooth {
+			contours = append(contours, smoothContour(points))
+		} else {
+			contour := make(Contour, len(points))
+			for i, p := range points {
+				contour[i] = ContourPoint{float64(p[0]), float64(p[1]), true}
+			}
+			contours = append(contours, contour)
+		}
+	}
+
+	return contours
+}
+
+// smoothContour turns a pixel edge outline into quadratic curves.  Corners
+// next to a one pixel run become control points, with on curve points at the
+// middle of the runs around them.
+func smoothContour(points [][2]int) Contour {
+	n := len(points)
+	// Runs are always horizontal or vertical.
+	runLength := func(a, b [2]int) int {
+		d := a[0]-b[0] + a[1]-b[1]
+		if d < 0 {
+			return -d
+		}
+		return d
+	}
+
+	sharp := make([]bool, n)
+	for i := range points {
+		prev := points[(i+n-1)%n]
+		next := points[(i+1)%n]
+		sharp[i] = runLength(prev, points[i]) > 1 && runLength(points[i], next) > 1
+	}
+
+	contour := Contour{}
+	for i, p := range points {
+		contour = append(contour, ContourPoint{float64(p[0]), float64(p[1]), sharp[i]})
+
+		j := (i+1) % n
+		if !sharp[i] || !sharp[j] {
+			q := points[j]
+			contour = append(contour, ContourPoint{
+				float64(p[0]+q[0]) / 2,
+				float64(p[1]+q[1]) / 2,
+				true,
+			})
+		}
+	}
+
+	return contour
+}
@@ -0,0 +1,114 @@
+package xeroxfont
+
+import (
+	"fmt"
+	"math"
+)
+
+// Embolden returns a copy of the font with each glyph smeared to the right by
+// strength pixels.  Proportional fonts have their CellWidth increased to
+// match, fixed fonts keep their cell width.
+func (f *Font) Embolden(strength int) (*Font, error) {
+	if strength < 1 {
+		return nil, fmt.Errorf("strength must be at least one")
+	}
+
+	if f.Header.Orientation != Portrait {
+		return nil, fmt.Errorf("only portrait fonts can be emboldened, not %s", f.Header.Orientation)
+	}
+
+	n := f.Clone()
+	for r, c := range n.Characters {
+		if c.IsSpace {
+			continue
+		}
+
+		src := f.Characters[r]
+		err := c.Resize(src.Width()+strength, src.Height())
+		if err != nil {
+			return nil, fmt.Errorf("character 0x%02X: %w", r, err)
+		}
+		c.clear()
+
+		for y := 0; y < src.Height(); y++ {
+			for x := 0; x < src.Width(); x++ {
+				if !src.Pixel(x, y) {
+					continue
+				}
+
+				for i := 0; i <= strength; i++ {
+					c.SetPixel(x+i, y, true)
+				}
+			}
+		}
+
+		if f.Header.FontType != 'F' {
+			c.CellWidth += strength
+		}
+	}
+
+	return n, nil
+}
+
+// Oblique returns a copy of the font with each glyph sheared to the right
+// around the baseline.  slant is the horizontal shift in pixels for each pixel
+// above the baseline (eg, 0.2 is about 11 degrees).  Bitmaps can't start left
+// of the cell, so every glyph is also moved right by the shear at the font's
+// lowest descender, keeping the baseline lined up across the font.  Bitmaps
+// are widened to fit the shear, but CellWidth is left alone.
+func (f *Font) Oblique(slant float64) (*Font, error) {
+	if slant <= 0 {
+		return nil, fmt.Errorf("slant must be positive")
+	}
+
+	if f.Header.Orientation != Portrait {
+		return nil, fmt.Errorf("only portrait fonts can be made oblique, not %s", f.Header.Orientation)
+	}
+
+	below := int(f.Header.DistanceBelow)
+	shift := func(c *Character, y int) int {
+		above := c.BlanksLeft + (c.Height() - y - 1) - below
+		return int(math.Round(slant * float64(above)))
+	}
+
+	// Offset that keeps the lowest row in the font from moving left of the
+	// cell.  The same offset is used for every glyph.
+	offset := 0
+	for _, c := range f.Characters {
+		if !c.IsSpace && c.Height() > 0 {
+			offset = max(offset, -shift(c, c.Height()-1))
+		}
+	}
+
+	n := f.Clone()
+	for r, c := range n.Characters {
+		if c.IsSpace {
+			continue
+		}
+		src := f.Characters[r]
+
+		// Shift for each row, based on its distance above the baseline.
+		shifts := make([]int, src.Height())
+		maxShift := 0
+		for y := range shifts {
+			shifts[y] = shift(src, y) + offset
+			maxShift = max(maxShift, shifts[y])
+		}
+
+		err := c.Resize(src.Width()+maxShift, src.Height())
+		if err != nil {
+			return nil, fmt.Errorf("character 0x%02X: %w", r, err)
+		}
+		c.clear()
+
+		for y := 0; y < src.Height(); y++ {
+			for x := 0; x < src.Width(); x++ {
+				if src.Pixel(x, y) {
+					c.SetPixel(x+shifts[y], y, true)
+				}
+			}
+		}
+	}
+
+	return n, nil
+}
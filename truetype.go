@@ -0,0 +1,652 @@
+package xeroxfont
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"unicode/utf16"
+)
+
+// TrueTypeOptions controls the outlines and names written by WriteTrueType().
+// Zero values are replaced with defaults.
+type TrueTypeOptions struct {
+	// How the bitmaps are traced.  Defaults to TracePixel.
+	Mode TraceMode
+
+	// Defaults to the family in the font's FontID, or the font name.
+	Family string
+
+	// Defaults to "Bold" for bold fonts and "Regular" otherwise.
+	Style string
+}
+
+func (o *TrueTypeOptions) setDefaults(f *Font) {
+	id, err := f.FontID()
+	if o.Family == "" {
+		if err == nil {
+			o.Family = id.FamilyName()
+		} else {
+			o.Family = f.psName()
+		}
+	}
+
+	if o.Style == "" {
+		if err == nil && id.Weight == WeightBold {
+			o.Style = "Bold"
+		} else {
+			o.Style = "Regular"
+		}
+	}
+}
+
+// ttfGlyph is a traced character scaled to font units.
+type ttfGlyph struct {
+	contours [][]ttfPoint
+	advance  int
+	xMin, yMin, xMax, yMax int
+}
+
+type ttfPoint struct {
+	x, y    int
+	onCurve bool
+}
+
+// WriteTrueType traces every character and writes the outlines as a TrueType
+// font.  One pixel is an even number of font units so the half pixel points
+// from TraceSmooth land on the grid.  The em is PixelHeight, the ascender and
+// descender are DistanceAbove and DistanceBelow, and the line gap is
+// DistanceLeading.
+func (f *Font) WriteTrueType(w io.Writer, opts TrueTypeOptions) error {
+	if f.Header.Orientation != Portrait {
+		return fmt.Errorf("only portrait fonts can be exported to TrueType, not %s", f.Header.Orientation)
+	}
+
+	if f.Header.PixelHeight == 0 {
+		return fmt.Errorf("font has no PixelHeight")
+	}
+
+	opts.setDefaults(f)
+
+	unitsPerPixel := 2 * max(1, 1024/int(f.Header.PixelHeight))
+	unitsPerEm := int(f.Header.PixelHeight) * unitsPerPixel
+	below := int(f.Header.DistanceBelow)
+
+	// Glyph zero is .notdef and is left empty.
+	glyphs := []*ttfGlyph{&ttfGlyph{advance: int(f.Header.FixedWidth) * unitsPerPixel}}
+	cmap := map[rune]int{}
+
+	for _, r := range f.sortedRunes() {
+		c := f.Characters[r]
+		g := &ttfGlyph{advance: int(c.CellWidth) * unitsPerPixel}
+		yoff := c.BlanksLeft - below
+
+		for _, contour := range c.Trace(opts.Mode) {
+			points := []ttfPoint{}
+			for _, p := range contour {
+				points = append(points, ttfPoint{
+					x: int(math.Round(p.X * float64(unitsPerPixel))),
+					y: int(math.Round((p.Y + float64(yoff)) * float64(unitsPerPixel))),
+					onCurve: p.OnCurve,
+				})
+			}
+			g.contours = append(g.contours, points)
+		}
+		g.bounds()
+
+		if u, ok := f.UnicodeFor(r); ok && u >= ' ' && u != 0x7F && u <= 0xFFFF {
+			if _, ok := cmap[u]; !ok {
+				cmap[u] = len(glyphs)
+			}
+		}
+		glyphs = append(glyphs, g)
+	}
+
+	if len(glyphs) > 0xFFFF {
+		return fmt.Errorf("too many glyphs: %d", len(glyphs))
+	}
+
+	font := &ttfFont{
+		Font: f,
+		opts: opts,
+		glyphs: glyphs,
+		cmap: cmap,
+		unitsPerEm: unitsPerEm,
+		unitsPerPixel: unitsPerPixel,
+	}
+	return font.write(w)
+}
+
+func (f *Font) WriteTrueTypeFile(filename string, opts TrueTypeOptions) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("unable to create %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	err = f.WriteTrueType(file, opts)
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func (g *ttfGlyph) bounds() {
+	first := true
+	for _, contour := range g.contours {
+		for _, p := range contour {
+			if first {
+				g.xMin, g.xMax, g.yMin, g.yMax = p.x, p.x, p.y, p.y
+				first = false
+				continue
+			}
+			g.xMin = min(g.xMin, p.x)
+			g.xMax = max(g.xMax, p.x)
+			g.yMin = min(g.yMin, p.y)
+			g.yMax = max(g.yMax, p.y)
+		}
+	}
+}
+
+// data returns the glyph in the glyf table's simple glyph format.
+func (g *ttfGlyph) data() []byte {
+	if len(g.contours) == 0 {
+		return nil
+	}
+
+	buf := &bytes.Buffer{}
+	be := binary.BigEndian
+	binary.Write(buf, be, int16(len(g.contours)))
+	binary.Write(buf, be, []int16{int16(g.xMin), int16(g.yMin), int16(g.xMax), int16(g.yMax)})
+
+	end := -1
+	for _, contour := range g.contours {
+		end += len(contour)
+		binary.Write(buf, be, uint16(end))
+	}
+	binary.Write(buf, be, uint16(0)) // no instructions
+
+	flags := []byte{}
+	xs, ys := &bytes.Buffer{}, &bytes.Buffer{}
+	px, py := 0, 0
+	for _, contour := range g.contours {
+		for _, p := range contour {
+			var flag byte
+			if p.onCurve {
+				flag |= 0x01
+			}
+			flag |= ttfDelta(xs, p.x-px, 0x02, 0x10)
+			flag |= ttfDelta(ys, p.y-py, 0x04, 0x20)
+			flags = append(flags, flag)
+			px, py = p.x, p.y
+		}
+	}
+
+	buf.Write(flags)
+	buf.Write(xs.Bytes())
+	buf.Write(ys.Bytes())
+	return buf.Bytes()
+}
+
+// ttfDelta writes a coordinate delta in its shortest form and returns the
+// flag bits that describe it.
+func ttfDelta(buf *bytes.Buffer, delta int, short, same byte) byte {
+	switch {
+	case delta == 0:
+		return same
+	case delta > 0 && delta < 256:
+		buf.WriteByte(byte(delta))
+		return short | same
+	case delta < 0 && delta > -256:
+		buf.WriteByte(byte(-delta))
+		return short
+	}
+	binary.Write(buf, binary.BigEndian, int16(delta))
+	return 0
+}
+
+type ttfFont struct {
+	*Font
+	opts   TrueTypeOptions
+	glyphs []*ttfGlyph
+	cmap   map[rune]int
+
+	unitsPerEm    int
+	unitsPerPixel int
+}
+
+func (t *ttfFont) write(w io.Writer) error {
+	glyf, loca := t.glyf()
+	tables := map[string][]byte{
+		"OS/2": t.os2(),
+		"cmap": t.cmapTable(),
+		"glyf": glyf,
+		"head": t.head(),
+		"hhea": t.hhea(),
+		"hmtx": t.hmtx(),
+		"loca": loca,
+		"maxp": t.maxp(),
+		"name": t.name(),
+		"post": t.post(),
+	}
+
+	tags := []string{}
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	be := binary.BigEndian
+	numTables := len(tags)
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= numTables {
+		entrySelector++
+	}
+	searchRange := (1 << entrySelector) * 16
+
+	out := &bytes.Buffer{}
+	binary.Write(out, be, uint32(0x00010000))
+	binary.Write(out, be, []uint16{
+		uint16(numTables),
+		uint16(searchRange),
+		uint16(entrySelector),
+		uint16(numTables*16 - searchRange),
+	})
+
+	offset := 12 + 16*numTables
+	headOffset := 0
+	for _, tag := range tags {
+		data := tables[tag]
+		if tag == "head" {
+			headOffset = offset
+		}
+
+		out.WriteString(tag)
+		binary.Write(out, be, []uint32{ttfChecksum(data), uint32(offset), uint32(len(data))})
+		offset += (len(data) + 3) &^ 3
+	}
+
+	for _, tag := range tags {
+		data := tables[tag]
+		out.Write(data)
+		out.Write(make([]byte, ((len(data)+3)&^3)-len(data)))
+	}
+
+	font := out.Bytes()
+	be.PutUint32(font[headOffset+8:], 0xB1B0AFBA-ttfChecksum(font))
+
+	_, err := w.Write(font)
+	return err
+}
+
+func ttfChecksum(data []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i += 4 {
+		word := make([]byte, 4)
+		copy(word, data[i:])
+		sum += binary.BigEndian.Uint32(word)
+	}
+	return sum
+}
+
+// bounds returns the bounding box of every glyph.
+func (t *ttfFont) bounds() (xMin, yMin, xMax, yMax int) {
+	first := true
+	for _, g := range t.glyphs {
+		if len(g.contours) == 0 {
+			continue
+		}
+		if first {
+			xMin, yMin, xMax, yMax = g.xMin, g.yMin, g.xMax, g.yMax
+			first = false
+			continue
+		}
+		xMin = min(xMin, g.xMin)
+		yMin = min(yMin, g.yMin)
+		xMax = max(xMax, g.xMax)
+		yMax = max(yMax, g.yMax)
+	}
+	return
+}
+
+func (t *ttfFont) ascender() int {
+	return int(t.Header.DistanceAbove) * t.unitsPerPixel
+}
+
+func (t *ttfFont) descender() int {
+	return -int(t.Header.DistanceBelow) * t.unitsPerPixel
+}
+
+func (t *ttfFont) lineGap() int {
+	return int(t.Header.DistanceLeading) * t.unitsPerPixel
+}
+
+func (t *ttfFont) bold() bool {
+	return t.opts.Style == "Bold"
+}
+
+func (t *ttfFont) glyf() (glyf, loca []byte) {
+	buf := &bytes.Buffer{}
+	offsets := []uint32{}
+	for _, g := range t.glyphs {
+		offsets = append(offsets, uint32(buf.Len()))
+		buf.Write(g.data())
+		buf.Write(make([]byte, (4-buf.Len()%4)%4))
+	}
+	offsets = append(offsets, uint32(buf.Len()))
+
+	l := &bytes.Buffer{}
+	binary.Write(l, binary.BigEndian, offsets)
+	return buf.Bytes(), l.Bytes()
+}
+
+func (t *ttfFont) head() []byte {
+	xMin, yMin, xMax, yMax := t.bounds()
+
+	var macStyle uint16
+	if t.bold() {
+		macStyle |= 0x01
+	}
+
+	buf := &bytes.Buffer{}
+	be := binary.BigEndian
+	binary.Write(buf, be, []uint32{
+		0x00010000, // version
+		0x00010000, // fontRevision
+		0,          // checkSumAdjustment, filled in last
+		0x5F0F3CF5, // magicNumber
+	})
+	binary.Write(buf, be, uint16(0x0009)) // baseline at 0, integer ppem
+	binary.Write(buf, be, uint16(t.unitsPerEm))
+	binary.Write(buf, be, []int64{0, 0}) // created, modified
+	binary.Write(buf, be, []int16{int16(xMin), int16(yMin), int16(xMax), int16(yMax)})
+	binary.Write(buf, be, []uint16{
+		macStyle,
+		8, // lowestRecPPEM
+	})
+	binary.Write(buf, be, []int16{
+		2, // fontDirectionHint
+		1, // indexToLocFormat, long offsets
+		0, // glyphDataFormat
+	})
+	return buf.Bytes()
+}
+
+func (t *ttfFont) hhea() []byte {
+	advanceMax := 0
+	minLsb, minRsb, maxExtent := 0, 0, 0
+	first := true
+	for _, g := range t.glyphs {
+		advanceMax = max(advanceMax, g.advance)
+		if len(g.contours) == 0 {
+			continue
+		}
+
+		rsb := g.advance - g.xMax
+		if first {
+			minLsb, minRsb, maxExtent = g.xMin, rsb, g.xMax
+			first = false
+			continue
+		}
+		minLsb = min(minLsb, g.xMin)
+		minRsb = min(minRsb, rsb)
+		maxExtent = max(maxExtent, g.xMax)
+	}
+
+	buf := &bytes.Buffer{}
+	be := binary.BigEndian
+	binary.Write(buf, be, uint32(0x00010000))
+	binary.Write(buf, be, []int16{
+		int16(t.ascender()),
+		int16(t.descender()),
+		int16(t.lineGap()),
+	})
+	binary.Write(buf, be, uint16(advanceMax))
+	binary.Write(buf, be, []int16{
+		int16(minLsb),
+		int16(minRsb),
+		int16(maxExtent),
+		1, 0, 0, // caretSlopeRise, caretSlopeRun, caretOffset
+		0, 0, 0, 0, // reserved
+		0, // metricDataFormat
+	})
+	binary.Write(buf, be, uint16(len(t.glyphs)))
+	return buf.Bytes()
+}
+
+func (t *ttfFont) hmtx() []byte {
+	buf := &bytes.Buffer{}
+	for _, g := range t.glyphs {
+		binary.Write(buf, binary.BigEndian, uint16(g.advance))
+		binary.Write(buf, binary.BigEndian, int16(g.xMin))
+	}
+	return buf.Bytes()
+}
+
+func (t *ttfFont) maxp() []byte {
+	maxPoints, maxContours := 0, 0
+	for _, g := range t.glyphs {
+		points := 0
+		for _, contour := range g.contours {
+			points += len(contour)
+		}
+		maxPoints = max(maxPoints, points)
+		maxContours = max(maxContours, len(g.contours))
+	}
+
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.BigEndian, uint32(0x00010000))
+	binary.Write(buf, binary.BigEndian, []uint16{
+		uint16(len(t.glyphs)),
+		uint16(maxPoints),
+		uint16(maxContours),
+		0, 0, // maxCompositePoints, maxCompositeContours
+		2,    // maxZones
+		0, 0, 0, 0, 0, 0, 0, 0,
+	})
+	return buf.Bytes()
+}
+
+func (t *ttfFont) os2() []byte {
+	_, yMin, _, yMax := t.bounds()
+	upp := t.unitsPerPixel
+
+	total, count := 0, 0
+	for _, g := range t.glyphs[1:] {
+		if g.advance > 0 {
+			total += g.advance
+			count++
+		}
+	}
+	avgWidth := 0
+	if count > 0 {
+		avgWidth = total / count
+	}
+
+	weight := uint16(400)
+	selection := uint16(0x40) // regular
+	if t.bold() {
+		weight = 700
+		selection = 0x20
+	}
+	selection |= 0x80 // use typo metrics
+
+	panose := make([]byte, 10)
+	if t.Header.FontType == 'F' {
+		panose[3] = 9 // monospaced
+	}
+
+	runes := []rune{}
+	for r := range t.cmap {
+		runes = append(runes, r)
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+	first, last := uint16(0), uint16(0)
+	if len(runes) > 0 {
+		first = uint16(min(runes[0], 0xFFFF))
+		last = uint16(min(runes[len(runes)-1], 0xFFFF))
+	}
+
+	inkHeight := func(r rune) int16 {
+		c, ok := t.Characters[r]
+		if !ok || c.IsSpace {
+			return 0
+		}
+		return int16((c.BlanksLeft - int(t.Header.DistanceBelow) + c.Height()) * upp)
+	}
+
+	em := int16(t.unitsPerEm)
+	buf := &bytes.Buffer{}
+	be := binary.BigEndian
+	binary.Write(buf, be, uint16(4)) // version
+	binary.Write(buf, be, int16(avgWidth))
+	binary.Write(buf, be, []uint16{weight, 5, 0}) // weight, width, fsType
+	binary.Write(buf, be, []int16{
+		em * 2 / 3, em * 2 / 3, 0, em / 7,    // subscript
+		em * 2 / 3, em * 2 / 3, 0, em * 2 / 5, // superscript
+		int16(upp * max(1, int(t.Header.PixelHeight)/20)), // yStrikeoutSize
+		em / 4, // yStrikeoutPosition
+		0,      // sFamilyClass
+	})
+	buf.Write(panose)
+	binary.Write(buf, be, []uint32{0, 0, 0, 0}) // ulUnicodeRange
+	buf.WriteString("XRX ")
+	binary.Write(buf, be, []uint16{selection, first, last})
+	binary.Write(buf, be, []int16{
+		int16(t.ascender()),
+		int16(t.descender()),
+		int16(t.lineGap()),
+	})
+	binary.Write(buf, be, []uint16{
+		uint16(max(yMax, t.ascender())),
+		uint16(max(-yMin, -t.descender())),
+	})
+	binary.Write(buf, be, []uint32{1, 0}) // ulCodePageRange, Latin 1
+	binary.Write(buf, be, []int16{inkHeight('x'), inkHeight('H')})
+	binary.Write(buf, be, []uint16{0, ' ', 1}) // default, break, max context
+	return buf.Bytes()
+}
+
+// cmapTable maps Unicode to glyphs with a single format 4 subtable.
+func (t *ttfFont) cmapTable() []byte {
+	runes := []rune{}
+	for r := range t.cmap {
+		if r <= 0xFFFF {
+			runes = append(runes, r)
+		}
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+
+	// Group runs of consecutive runes that map to consecutive glyphs.
+	type segment struct{ start, end, delta int }
+	segments := []segment{}
+	for _, r := range runes {
+		delta := t.cmap[r] - int(r)
+		n := len(segments)
+		if n > 0 && segments[n-1].end == int(r)-1 && segments[n-1].delta == delta {
+			segments[n-1].end = int(r)
+			continue
+		}
+		segments = append(segments, segment{int(r), int(r), delta})
+	}
+	segments = append(segments, segment{0xFFFF, 0xFFFF, 1})
+
+	segCount := len(segments)
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= segCount {
+		entrySelector++
+	}
+	searchRange := (1 << entrySelector) * 2
+
+	sub := &bytes.Buffer{}
+	be := binary.BigEndian
+	binary.Write(sub, be, []uint16{
+		4, // format
+		uint16(16 + segCount*8),
+		0, // language
+		uint16(segCount * 2),
+		uint16(searchRange),
+		uint16(entrySelector),
+		uint16(segCount*2 - searchRange),
+	})
+	for _, s := range segments {
+		binary.Write(sub, be, uint16(s.end))
+	}
+	binary.Write(sub, be, uint16(0)) // reservedPad
+	for _, s := range segments {
+		binary.Write(sub, be, uint16(s.start))
+	}
+	for _, s := range segments {
+		binary.Write(sub, be, uint16(s.delta))
+	}
+	for range segments {
+		binary.Write(sub, be, uint16(0)) // idRangeOffset
+	}
+
+	buf := &bytes.Buffer{}
+	binary.Write(buf, be, []uint16{0, 1, 3, 1}) // version, numTables, Windows Unicode BMP
+	binary.Write(buf, be, uint32(12))
+	buf.Write(sub.Bytes())
+	return buf.Bytes()
+}
+
+func (t *ttfFont) name() []byte {
+	full := t.opts.Family
+	if t.opts.Style != "Regular" {
+		full += " " + t.opts.Style
+	}
+
+	psName := t.psName()
+	names := []struct {
+		id   uint16
+		text string
+	}{
+		{1, t.opts.Family},
+		{2, t.opts.Style},
+		{3, "Xerox:" + psName},
+		{4, full},
+		{5, "Version 1.000"},
+		{6, psName},
+	}
+
+	be := binary.BigEndian
+	strs := &bytes.Buffer{}
+	records := &bytes.Buffer{}
+	for _, n := range names {
+		encoded := utf16.Encode([]rune(n.text))
+		binary.Write(records, be, []uint16{
+			3, 1, 0x0409, // Windows, Unicode BMP, English
+			n.id,
+			uint16(len(encoded) * 2),
+			uint16(strs.Len()),
+		})
+		binary.Write(strs, be, encoded)
+	}
+
+	buf := &bytes.Buffer{}
+	binary.Write(buf, be, []uint16{0, uint16(len(names)), uint16(6 + 12*len(names))})
+	buf.Write(records.Bytes())
+	buf.Write(strs.Bytes())
+	return buf.Bytes()
+}
+
+// post is version 3, which has no glyph names.
+func (t *ttfFont) post() []byte {
+	var fixed uint32
+	if t.Header.FontType == 'F' {
+		fixed = 1
+	}
+
+	buf := &bytes.Buffer{}
+	be := binary.BigEndian
+	binary.Write(buf, be, []uint32{0x00030000, 0}) // version, italicAngle
+	binary.Write(buf, be, []int16{
+		int16(t.descender() / 2),  // underlinePosition
+		int16(t.unitsPerPixel * max(1, int(t.Header.PixelHeight)/20)), // underlineThickness
+	})
+	binary.Write(buf, be, []uint32{fixed, 0, 0, 0, 0})
+	return buf.Bytes()
+}
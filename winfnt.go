@@ -0,0 +1,517 @@
+package xeroxfont
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// WinFNTOptions controls the Windows bitmap font written by WinFNT() and
+// WriteFON().  Zero values are replaced with defaults.
+type WinFNTOptions struct {
+	// Resource version, 2 or 3.  Version 2 offsets are 16 bit, so the default
+	// is 2 when the font fits in 64k and 3 when it doesn't.
+	Version int
+
+	// Resolution written to dfVertRes and dfHorizRes.  Defaults to
+	// DeviceDPI.
+	DPI int
+
+	// Defaults to the size in the font's name, or PixelHeight at DPI.
+	PointSize int
+
+	// Defaults to the family in the font's FontID, or the font name.
+	Face string
+
+	Copyright string
+}
+
+func (o *WinFNTOptions) setDefaults(f *Font) {
+	if o.DPI <= 0 {
+		o.DPI = DeviceDPI
+	}
+
+	id, err := f.FontID()
+	if o.PointSize <= 0 {
+		if err == nil {
+			o.PointSize = id.PointSize
+		} else {
+			o.PointSize = (int(f.Header.PixelHeight)*72 + o.DPI/2) / o.DPI
+		}
+	}
+
+	if o.Face == "" {
+		if err == nil {
+			o.Face = id.FamilyName()
+		} else {
+			o.Face = f.psName()
+		}
+	}
+}
+
+// Windows 2.x font resource header.
+type winFNTHeader struct {
+	Version   uint16
+	Size      uint32
+	Copyright [60]byte
+	Type      uint16
+	Points    uint16
+	VertRes   uint16
+	HorizRes  uint16
+	Ascent    uint16
+
+	InternalLeading uint16
+	ExternalLeading uint16
+
+	Italic    byte
+	Underline byte
+	StrikeOut byte
+	Weight    uint16
+	CharSet   byte
+	PixWidth  uint16
+	PixHeight uint16
+
+	PitchAndFamily byte
+
+	AvgWidth    uint16
+	MaxWidth    uint16
+	FirstChar   byte
+	LastChar    byte
+	DefaultChar byte // relative to FirstChar
+	BreakChar   byte // relative to FirstChar
+	WidthBytes  uint16
+	Device      uint32
+	Face        uint32
+	BitsPointer uint32
+	BitsOffset  uint32
+	Reserved    byte
+}
+
+// Fields added to the header in Windows 3.0 fonts.
+type winFNTHeader3 struct {
+	Flags        uint32
+	Aspace       uint16
+	Bspace       uint16
+	Cspace       uint16
+	ColorPointer uint32
+	Reserved1    [16]byte
+}
+
+const (
+	winFNTDirEntrySize = 0x71 // header bytes up to and including dfBitsPointer
+
+	winFixed        = 0x0001
+	winProportional = 0x0002
+	win1Color       = 0x0010
+)
+
+// WinFNT returns the font as a Windows bitmap font resource.  Windows glyphs
+// all share one height and can't draw outside of their cell, so each glyph is
+// placed in a CellWidth wide cell tall enough for the ink of every character.
+// Ink past the right edge of a cell is clipped.
+func (f *Font) WinFNT(opts WinFNTOptions) ([]byte, error) {
+	if f.Header.Orientation != Portrait {
+		return nil, fmt.Errorf("only portrait fonts can be exported to Windows fonts, not %s", f.Header.Orientation)
+	}
+
+	opts.setDefaults(f)
+
+	chars := []*Character{}
+	for _, r := range f.sortedRunes() {
+		if r >= 0 && r <= 255 {
+			chars = append(chars, f.Characters[r])
+		}
+	}
+	if len(chars) == 0 {
+		return nil, fmt.Errorf("no characters to export")
+	}
+
+	first, last := chars[0].Value, chars[len(chars)-1].Value
+
+	// Fit the cell around the header's ascent and descent as well as the ink.
+	below := int(f.Header.DistanceBelow)
+	ascent, descent := int(f.Header.DistanceAbove), below
+	maxWidth, fixed := 0, true
+	for _, c := range chars {
+		maxWidth = max(maxWidth, int(c.CellWidth))
+		fixed = fixed && int(c.CellWidth) == int(chars[0].CellWidth)
+		if c.IsSpace {
+			continue
+		}
+		yoff := c.BlanksLeft - below
+		ascent = max(ascent, yoff+c.Height())
+		descent = max(descent, -yoff)
+	}
+	height := ascent + descent
+
+	defaultChar := first
+	if _, ok := f.Characters['?']; ok && '?' >= first && '?' <= last {
+		defaultChar = '?'
+	}
+
+	breakChar := first
+	if ' ' >= first && ' ' <= last {
+		breakChar = ' '
+	}
+
+	// Glyph bitmaps are stored a column of bytes at a time, top to bottom.
+	bits := &bytes.Buffer{}
+	offsets := map[rune]int{}
+	widthBytes := 0
+	for _, c := range chars {
+		offsets[c.Value] = bits.Len()
+		width := int(c.CellWidth)
+		widthBytes += (width + 7) / 8
+		top := ascent - (c.BlanksLeft - below + c.Height())
+
+		for col := 0; col < width; col += 8 {
+			for y := 0; y < height; y++ {
+				b := byte(0)
+				for i := 0; i < 8; i++ {
+					gy := y - top
+					if !c.IsSpace && col+i < width && gy >= 0 && gy < c.Height() && c.Pixel(col+i, gy) {
+						b |= 0x80 >> i
+					}
+				}
+				bits.WriteByte(b)
+			}
+		}
+	}
+
+	headerSize := binary.Size(winFNTHeader{})
+	entrySize := 4
+	if opts.Version == 0 {
+		opts.Version = 2
+		if headerSize+int(last-first+2)*entrySize+bits.Len() > 0xFFFF {
+			opts.Version = 3
+		}
+	}
+
+	switch opts.Version {
+	case 2:
+	case 3:
+		headerSize += binary.Size(winFNTHeader3{})
+		entrySize = 6
+	default:
+		return nil, fmt.Errorf("unsupported Windows font version: %d", opts.Version)
+	}
+
+	bitsOffset := headerSize + int(last-first+2)*entrySize
+	faceOffset := bitsOffset + bits.Len()
+	size := faceOffset + len(opts.Face) + 1
+	if opts.Version == 2 && faceOffset > 0xFFFF {
+		return nil, fmt.Errorf("font is too large for a version 2 resource")
+	}
+
+	// Every code in the range needs an entry.  Missing characters use the
+	// default character and the last entry is a blank space character.
+	table := &bytes.Buffer{}
+	entry := func(width, offset int) {
+		binary.Write(table, binary.LittleEndian, uint16(width))
+		if opts.Version == 2 {
+			binary.Write(table, binary.LittleEndian, uint16(bitsOffset+offset))
+		} else {
+			binary.Write(table, binary.LittleEndian, uint32(bitsOffset+offset))
+		}
+	}
+
+	for r := first; r <= last; r++ {
+		c, ok := f.Characters[r]
+		if !ok {
+			c = f.Characters[defaultChar]
+		}
+		entry(int(c.CellWidth), offsets[c.Value])
+	}
+	entry(0, bits.Len())
+
+	id, idErr := f.FontID()
+
+	header := winFNTHeader{
+		Version: uint16(opts.Version) << 8,
+		Size: uint32(size),
+		Points: uint16(opts.PointSize),
+		VertRes: uint16(opts.DPI),
+		HorizRes: uint16(opts.DPI),
+		Ascent: uint16(ascent),
+		InternalLeading: uint16(max(0, height-int(f.Header.PixelHeight))),
+		ExternalLeading: f.Header.DistanceLeading,
+		Weight: 400,
+		PixHeight: uint16(height),
+		AvgWidth: uint16(maxWidth),
+		MaxWidth: uint16(maxWidth),
+		FirstChar: byte(first),
+		LastChar: byte(last),
+		DefaultChar: byte(defaultChar - first),
+		BreakChar: byte(breakChar - first),
+		WidthBytes: uint16((widthBytes + 1) &^ 1),
+		Face: uint32(faceOffset),
+		BitsOffset: uint32(bitsOffset),
+	}
+	copy(header.Copyright[:], opts.Copyright)
+
+	if idErr == nil && id.Weight == WeightBold {
+		header.Weight = 700
+	}
+
+	if c, ok := f.Characters['x']; ok {
+		header.AvgWidth = uint16(c.CellWidth)
+	}
+
+	// The low bit of PitchAndFamily is set for variable pitch fonts.
+	flags := uint32(win1Color)
+	if fixed {
+		header.PixWidth = uint16(maxWidth)
+		header.PitchAndFamily = 0x30 // FF_MODERN
+		flags |= winFixed
+	} else {
+		header.PitchAndFamily = 0x01
+		flags |= winProportional
+	}
+
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.LittleEndian, header)
+	if opts.Version == 3 {
+		binary.Write(buf, binary.LittleEndian, winFNTHeader3{Flags: flags})
+	}
+	buf.Write(table.Bytes())
+	buf.Write(bits.Bytes())
+	buf.WriteString(opts.Face)
+	buf.WriteByte(0)
+	return buf.Bytes(), nil
+}
+
+func (f *Font) WriteWinFNT(w io.Writer, opts WinFNTOptions) error {
+	data, err := f.WinFNT(opts)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+// NE executable header.  Offsets are relative to the start of this header
+// unless noted otherwise.
+type neHeader struct {
+	Magic          [2]byte
+	LinkerVersion  byte
+	LinkerRevision byte
+
+	EntryTableOffset uint16
+	EntryTableLength uint16
+
+	CRC             uint32
+	Flags           uint16
+	AutoDataSegment uint16
+	HeapSize        uint16
+	StackSize       uint16
+	CSIP            uint32
+	SSSP            uint32
+	SegmentCount    uint16
+	ModuleRefCount  uint16
+
+	NonResidentNameSize uint16
+	SegmentTableOffset  uint16
+	ResourceTableOffset uint16
+	ResidentNameOffset  uint16
+	ModuleRefOffset     uint16
+	ImportedNameOffset  uint16
+
+	NonResidentNameOffset uint32 // from the start of the file
+
+	MovableEntryCount    uint16
+	AlignmentShift       uint16
+	ResourceSegmentCount uint16
+	TargetOS             byte
+	OtherFlags           byte
+	GangloadOffset       uint16
+	GangloadLength       uint16
+	MinCodeSwap          uint16
+	WindowsVersion       uint16
+}
+
+const (
+	neOffset     = 0x80
+	neAlignShift = 4
+
+	rtFontDir = 0x8007
+	rtFont    = 0x8008
+
+	neResourceFlags = 0x1C30 // moveable, pure, discardable
+)
+
+// DOS stub that prints a message and exits, followed by the message.
+var dosStub = append([]byte{
+	0x0E,             // push cs
+	0x1F,             // pop ds
+	0xBA, 0x0E, 0x00, // mov dx, message
+	0xB4, 0x09,       // mov ah, 9
+	0xCD, 0x21,       // int 21h
+	0xB8, 0x01, 0x4C, // mov ax, 4C01h
+	0xCD, 0x21,       // int 21h
+}, "This is a Windows font file.\r\n$"...)
+
+// WriteFON writes the fonts as Windows font resources in a .FON file.  The
+// module name defaults to the first font's name.
+func WriteFON(w io.Writer, module string, fonts []*Font, opts WinFNTOptions) error {
+	if len(fonts) == 0 {
+		return fmt.Errorf("no fonts to write")
+	}
+
+	resources := [][]byte{}
+	faces := []string{}
+	for _, font := range fonts {
+		o := opts
+		o.setDefaults(font)
+		data, err := font.WinFNT(o)
+		if err != nil {
+			return fmt.Errorf("%s: %w", font.psName(), err)
+		}
+		resources = append(resources, data)
+		faces = append(faces, o.Face)
+	}
+
+	if module == "" {
+		module = fonts[0].psName()
+	}
+	module = strings.ToUpper(module)
+
+	// The font directory repeats the start of each font's header.
+	fontDir := &bytes.Buffer{}
+	binary.Write(fontDir, binary.LittleEndian, uint16(len(resources)))
+	for i, data := range resources {
+		binary.Write(fontDir, binary.LittleEndian, uint16(i+1))
+		fontDir.Write(data[:winFNTDirEntrySize])
+		fontDir.WriteByte(0) // device name
+		fontDir.WriteString(faces[i])
+		fontDir.WriteByte(0)
+	}
+	resources = append([][]byte{fontDir.Bytes()}, resources...)
+
+	// Lay out the tables following the NE header.
+	headerSize := binary.Size(neHeader{})
+	resTableSize := 2 + (8 + 12) + (8 + 12*len(fonts)) + 2 + len("FONTDIR") + 1 + 1
+	resTable := headerSize
+	resident := resTable + resTableSize
+	residentSize := 1 + len(module) + 2 + 1
+	imported := resident + residentSize
+	entry := imported + 1
+	nonResident := neOffset + entry + 1
+
+	dpi := opts.DPI
+	if dpi <= 0 {
+		dpi = DeviceDPI
+	}
+	desc := fmt.Sprintf("FONTRES 100,%d,%d : %s", dpi, dpi, faces[0])
+	nonResidentSize := 1 + len(desc) + 2 + 1
+
+	align := 1 << neAlignShift
+	offset := (nonResident + nonResidentSize + align - 1) &^ (align - 1)
+	offsets := []int{}
+	for _, data := range resources {
+		offsets = append(offsets, offset)
+		offset += (len(data) + align - 1) &^ (align - 1)
+	}
+
+	out := &bytes.Buffer{}
+	le := binary.LittleEndian
+
+	// MZ header and stub
+	mz := make([]byte, 0x40)
+	copy(mz, "MZ")
+	le.PutUint16(mz[0x02:], neOffset)     // bytes in the last page
+	le.PutUint16(mz[0x04:], 1)            // pages
+	le.PutUint16(mz[0x08:], 4)            // header paragraphs
+	le.PutUint16(mz[0x0C:], 0xFFFF)       // max alloc
+	le.PutUint16(mz[0x10:], 0xB8)         // sp
+	le.PutUint16(mz[0x18:], 0x40)         // relocation table
+	le.PutUint32(mz[0x3C:], neOffset)
+	out.Write(mz)
+	out.Write(dosStub)
+	out.Write(make([]byte, neOffset-out.Len()))
+
+	binary.Write(out, le, neHeader{
+		Magic: [2]byte{'N', 'E'},
+		LinkerVersion: 5,
+		LinkerRevision: 10,
+		EntryTableOffset: uint16(entry),
+		EntryTableLength: 1,
+		Flags: 0x8000, // library module
+		NonResidentNameSize: uint16(nonResidentSize),
+		SegmentTableOffset: uint16(resTable),
+		ResourceTableOffset: uint16(resTable),
+		ResidentNameOffset: uint16(resident),
+		ModuleRefOffset: uint16(imported),
+		ImportedNameOffset: uint16(imported),
+		NonResidentNameOffset: uint32(nonResident),
+		AlignmentShift: neAlignShift,
+		TargetOS: 2, // Windows
+		WindowsVersion: 0x0300,
+	})
+
+	// Resource table
+	resource := func(i int, id uint16) {
+		binary.Write(out, le, []uint16{
+			uint16(offsets[i] >> neAlignShift),
+			uint16((len(resources[i]) + align - 1) >> neAlignShift),
+			neResourceFlags,
+			id,
+		})
+		binary.Write(out, le, uint32(0))
+	}
+
+	binary.Write(out, le, uint16(neAlignShift))
+	binary.Write(out, le, []uint16{rtFontDir, 1})
+	binary.Write(out, le, uint32(0))
+	resource(0, uint16(resTableSize-len("FONTDIR")-2)) // named resource
+	binary.Write(out, le, []uint16{rtFont, uint16(len(fonts))})
+	binary.Write(out, le, uint32(0))
+	for i := range fonts {
+		resource(i+1, uint16(0x8000|(i+1)))
+	}
+	binary.Write(out, le, uint16(0))
+	out.WriteByte(byte(len("FONTDIR")))
+	out.WriteString("FONTDIR")
+	out.WriteByte(0)
+
+	// Resident names, an empty imported name table and an empty entry
+	// table.
+	out.WriteByte(byte(len(module)))
+	out.WriteString(module)
+	binary.Write(out, le, uint16(0))
+	out.WriteByte(0)
+	out.WriteByte(0)
+	out.WriteByte(0)
+
+	// Non-resident names hold the font description.
+	out.WriteByte(byte(len(desc)))
+	out.WriteString(desc)
+	binary.Write(out, le, uint16(0))
+	out.WriteByte(0)
+
+	for i, data := range resources {
+		out.Write(make([]byte, offsets[i]-out.Len()))
+		out.Write(data)
+	}
+	out.Write(make([]byte, offset-out.Len()))
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+func WriteFONFile(filename, module string, fonts []*Font, opts WinFNTOptions) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("unable to create %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	err = WriteFON(file, module, fonts, opts)
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}